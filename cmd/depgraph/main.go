@@ -0,0 +1,175 @@
+// depgraph prints the import graph of a set of packages as Graphviz
+// DOT or as JSON, to help visualize and audit the dependencies of a
+// program.
+package main // import "github.com/gm42/go-tools/cmd/depgraph"
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+var (
+	fFormat         string
+	fCollapseStd    bool
+	fCollapseVendor bool
+	fTags           buildutil.TagsFlag
+)
+
+func init() {
+	flag.StringVar(&fFormat, "f", "dot", "Output `format` (valid choices are 'dot' and 'json')")
+	flag.BoolVar(&fCollapseStd, "collapse-std", false, "Collapse all standard library packages into a single node")
+	flag.BoolVar(&fCollapseVendor, "collapse-vendor", false, "Collapse all packages in a vendor directory into a single node")
+	flag.Var(&fTags, "tags", "List of build tags")
+}
+
+// stdNode is the name of the node that standard library packages are
+// collapsed into.
+const stdNode = "std"
+
+// Node is a package, or a group of collapsed packages, and the nodes
+// it imports.
+type Node struct {
+	Package string   `json:"package"`
+	Imports []string `json:"imports"`
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if len(flag.Args()) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	var emit func([]Node)
+	switch fFormat {
+	case "dot":
+		emit = emitDOT
+	case "json":
+		emit = emitJSON
+	default:
+		log.Fatalf("unsupported output format %q", fFormat)
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = fTags
+	conf := loader.Config{
+		Build: &ctx,
+		// Only the package graph matters, not the code.
+		TypeCheckFuncBodies: func(string) bool { return false },
+	}
+	for _, path := range gotool.ImportPaths(flag.Args()) {
+		conf.Import(path)
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var roots []*types.Package
+	for _, pkg := range lprog.InitialPackages() {
+		roots = append(roots, pkg.Pkg)
+	}
+	emit(graph(roots, namer(&ctx)))
+}
+
+// namer returns a function mapping import paths to node names,
+// taking the collapse flags into account.
+func namer(ctx *build.Context) func(path string) string {
+	std := map[string]bool{}
+	isStd := func(path string) bool {
+		is, ok := std[path]
+		if !ok {
+			bpkg, err := ctx.Import(path, "", build.FindOnly)
+			is = err == nil && bpkg.Goroot
+			std[path] = is
+		}
+		return is
+	}
+	return func(path string) string {
+		if fCollapseStd && isStd(path) {
+			return stdNode
+		}
+		if fCollapseVendor {
+			if i := strings.LastIndex(path, "/vendor/"); i != -1 {
+				return path[:i+len("/vendor")]
+			}
+			if strings.HasPrefix(path, "vendor/") {
+				return "vendor"
+			}
+		}
+		return path
+	}
+}
+
+// graph returns the nodes reachable from roots, sorted by name.
+func graph(roots []*types.Package, name func(string) string) []Node {
+	edges := map[string]map[string]bool{}
+	seen := map[*types.Package]bool{}
+	var visit func(pkg *types.Package)
+	visit = func(pkg *types.Package) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		from := name(pkg.Path())
+		if edges[from] == nil {
+			edges[from] = map[string]bool{}
+		}
+		for _, imp := range pkg.Imports() {
+			if to := name(imp.Path()); to != from {
+				edges[from][to] = true
+			}
+			visit(imp)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	nodes := make([]Node, 0, len(edges))
+	for from, tos := range edges {
+		n := Node{Package: from, Imports: []string{}}
+		for to := range tos {
+			n.Imports = append(n.Imports, to)
+		}
+		sort.Strings(n.Imports)
+		nodes = append(nodes, n)
+	}
+	sort.Sort(byPackage(nodes))
+	return nodes
+}
+
+type byPackage []Node
+
+func (ns byPackage) Len() int           { return len(ns) }
+func (ns byPackage) Swap(i, j int)      { ns[i], ns[j] = ns[j], ns[i] }
+func (ns byPackage) Less(i, j int) bool { return ns[i].Package < ns[j].Package }
+
+func emitJSON(nodes []Node) {
+	json.NewEncoder(os.Stdout).Encode(nodes)
+}
+
+func emitDOT(nodes []Node) {
+	fmt.Println("digraph imports {")
+	for _, n := range nodes {
+		fmt.Printf("\t%q;\n", n.Package)
+	}
+	for _, n := range nodes {
+		for _, imp := range n.Imports {
+			fmt.Printf("\t%q -> %q;\n", n.Package, imp)
+		}
+	}
+	fmt.Println("}")
+}
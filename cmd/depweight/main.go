@@ -0,0 +1,160 @@
+// depweight reports how much each dependency of a package contributes
+// to the size of the program, to help understand why a binary or an
+// analysis is heavy.
+package main // import "github.com/gm42/go-tools/cmd/depweight"
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+var (
+	fJSON bool
+	fTop  int
+	fTags buildutil.TagsFlag
+)
+
+func init() {
+	flag.BoolVar(&fJSON, "json", false, "Format data as JSON")
+	flag.IntVar(&fTop, "n", 20, "Only show the `N` heaviest packages; 0 shows all")
+	flag.Var(&fTags, "tags", "List of build tags")
+}
+
+// Weight describes the contribution of a single package.
+type Weight struct {
+	Package string `json:"package"`
+	// Functions is the number of SSA functions in the package itself,
+	// including anonymous functions and wrappers.
+	Functions int `json:"functions"`
+	// Packages is the size of the package's transitive import
+	// closure, including the package itself.
+	Packages int `json:"packages"`
+	// TransitiveFunctions is the number of SSA functions in the
+	// package's transitive import closure.
+	TransitiveFunctions int `json:"transitive_functions"`
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if len(flag.Args()) != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = fTags
+	conf := loader.Config{
+		Build: &ctx,
+	}
+	conf.Import(flag.Args()[0])
+
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ssaprog := ssautil.CreateProgram(lprog, 0)
+	ssaprog.Build()
+
+	// The package is stored under its import path, which differs
+	// from the argument if that is a relative path such as ".".
+	root := lprog.InitialPackages()[0].Pkg
+	weights := compute(root, ssautil.AllFunctions(ssaprog))
+	if fTop > 0 && len(weights) > fTop {
+		weights = weights[:fTop]
+	}
+	if fJSON {
+		emitJSON(weights)
+	} else {
+		emitText(weights)
+	}
+}
+
+// compute returns the weights of root and all of its dependencies,
+// heaviest first.
+func compute(root *types.Package, fns map[*ssa.Function]bool) []Weight {
+	own := map[*types.Package]int{}
+	for fn := range fns {
+		if fn.Pkg == nil {
+			// Wrappers and other synthetic functions without a
+			// package are attributed to the package of their
+			// receiver or object, if any.
+			if obj := fn.Object(); obj != nil && obj.Pkg() != nil {
+				own[obj.Pkg()]++
+			}
+			continue
+		}
+		own[fn.Pkg.Pkg]++
+	}
+
+	closures := map[*types.Package]map[*types.Package]bool{}
+	var closure func(pkg *types.Package) map[*types.Package]bool
+	closure = func(pkg *types.Package) map[*types.Package]bool {
+		if c, ok := closures[pkg]; ok {
+			return c
+		}
+		c := map[*types.Package]bool{pkg: true}
+		closures[pkg] = c
+		for _, imp := range pkg.Imports() {
+			for dep := range closure(imp) {
+				c[dep] = true
+			}
+		}
+		return c
+	}
+
+	var weights []Weight
+	for pkg := range closure(root) {
+		w := Weight{
+			Package:   pkg.Path(),
+			Functions: own[pkg],
+		}
+		for dep := range closure(pkg) {
+			w.Packages++
+			w.TransitiveFunctions += own[dep]
+		}
+		weights = append(weights, w)
+	}
+	sort.Sort(byWeight(weights))
+	return weights
+}
+
+type byWeight []Weight
+
+func (ws byWeight) Len() int      { return len(ws) }
+func (ws byWeight) Swap(i, j int) { ws[i], ws[j] = ws[j], ws[i] }
+func (ws byWeight) Less(i, j int) bool {
+	if ws[i].TransitiveFunctions != ws[j].TransitiveFunctions {
+		return ws[i].TransitiveFunctions > ws[j].TransitiveFunctions
+	}
+	return ws[i].Package < ws[j].Package
+}
+
+func emitJSON(weights []Weight) {
+	if weights == nil {
+		weights = []Weight{}
+	}
+	json.NewEncoder(os.Stdout).Encode(weights)
+}
+
+func emitText(weights []Weight) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRANSITIVE FUNCS\tPACKAGES\tFUNCS\tPACKAGE")
+	for _, w := range weights {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\n", w.TransitiveFunctions, w.Packages, w.Functions, w.Package)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,25 @@
+// doccheck reports missing and malformed documentation comments.
+package main // import "github.com/gm42/go-tools/cmd/doccheck"
+
+import (
+	"flag"
+
+	"github.com/gm42/go-tools/doccheck"
+	"github.com/gm42/go-tools/internal/driver"
+	"github.com/gm42/go-tools/lint"
+)
+
+func main() {
+	var gen bool
+	driver.Main(driver.Tool{
+		Name: "doccheck",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&gen, "generated", false, "Check generated code")
+		},
+		Checkers: func() []lint.Checker {
+			c := doccheck.NewChecker()
+			c.CheckGenerated = gen
+			return []lint.Checker{c}
+		},
+	})
+}
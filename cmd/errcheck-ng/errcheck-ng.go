@@ -1,13 +1,40 @@
 package main // import "github.com/gm42/go-tools/cmd/errcheck-ng"
 
 import (
-	"os"
+	"flag"
+	"strings"
 
 	"github.com/gm42/go-tools/errcheck"
-	"github.com/gm42/go-tools/lint/lintutil"
+	"github.com/gm42/go-tools/internal/driver"
+	"github.com/gm42/go-tools/lint"
 )
 
 func main() {
-	c := errcheck.NewChecker()
-	lintutil.ProcessArgs("errcheck-ng", c, os.Args[1:])
+	var (
+		blank, swallow bool
+		stores         errcheck.StorePolicy
+	)
+	sinks := strings.Join(errcheck.DefaultSinks, ",")
+	driver.Main(driver.Tool{
+		Name: "errcheck-ng",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&blank, "blank", false, "Report errors assigned to the blank identifier")
+			fs.BoolVar(&swallow, "swallow", false, "Report functions that receive errors but never return, wrap, log or compare them")
+			fs.Var(&stores, "stores", "Whether errors stored in struct fields, slices and maps count as handled: handled, read (if the stored error is read again) or unhandled")
+			fs.StringVar(&sinks, "sinks", sinks, "Comma-separated list of `functions` that are considered to handle errors passed to them")
+		},
+		Checkers: func() []lint.Checker {
+			c := errcheck.NewChecker()
+			c.Blank = blank
+			c.Swallow = swallow
+			c.Stores = stores
+			c.Sinks = map[string]bool{}
+			for _, sink := range strings.Split(sinks, ",") {
+				if sink = strings.TrimSpace(sink); sink != "" {
+					c.Sinks[sink] = true
+				}
+			}
+			return []lint.Checker{c}
+		},
+	})
 }
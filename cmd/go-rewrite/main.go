@@ -0,0 +1,8 @@
+// go-rewrite applies type-aware rewrite rules to Go packages.
+package main // import "github.com/gm42/go-tools/cmd/go-rewrite"
+
+import "github.com/gm42/go-tools/rewrite"
+
+func main() {
+	rewrite.Main()
+}
@@ -1,43 +1,33 @@
-// gochk runs staticcheck, gosimple and unused.
+// gochk runs staticcheck, gosimple and unused, and optionally
+// doccheck.
 package main // import "github.com/gm42/go-tools/cmd/gochk"
 
 import (
-	"os"
+	"flag"
+	"strings"
 
+	"github.com/gm42/go-tools/doccheck"
+	"github.com/gm42/go-tools/internal/driver"
 	"github.com/gm42/go-tools/lint"
-	"github.com/gm42/go-tools/lint/lintutil"
 	"github.com/gm42/go-tools/simple"
 	"github.com/gm42/go-tools/staticcheck"
 	"github.com/gm42/go-tools/unused"
 )
 
-type Checker struct {
-	Checkers []lint.Checker
-}
-
-func (c *Checker) Init(prog *lint.Program) {
-	for _, cc := range c.Checkers {
-		cc.Init(prog)
-	}
-}
-
-func (c *Checker) Funcs() map[string]lint.Func {
-	fns := map[string]lint.Func{}
-	for _, cc := range c.Checkers {
-		for k, v := range cc.Funcs() {
-			fns[k] = v
-		}
-	}
-	return fns
-}
-
 func main() {
 	var flags struct {
 		staticcheck struct {
 			enabled   bool
 			generated bool
+			style     bool
+			archs     string
 		}
 		gosimple struct {
+			enabled           bool
+			generated         bool
+			httpDefaultClient bool
+		}
+		doc struct {
 			enabled   bool
 			generated bool
 		}
@@ -53,71 +43,96 @@ func main() {
 			reflection   bool
 		}
 	}
-	fs := lintutil.FlagSet("gochk")
-	fs.BoolVar(&flags.staticcheck.enabled,
-		"staticcheck.enabled", true, "Run staticcheck")
-	fs.BoolVar(&flags.staticcheck.generated,
-		"staticcheck.generated", false, "Check generated code (only applies to a subset of checks)")
+	driver.Main(driver.Tool{
+		Name: "gochk",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&flags.staticcheck.enabled,
+				"staticcheck.enabled", true, "Run staticcheck")
+			fs.BoolVar(&flags.staticcheck.generated,
+				"staticcheck.generated", false, "Check generated code (only applies to a subset of checks)")
+			fs.BoolVar(&flags.staticcheck.style,
+				"staticcheck.style", true, "Run stylistic checks (ST1???)")
+			fs.StringVar(&flags.staticcheck.archs,
+				"staticcheck.archs", "", "Comma-separated list of `architectures` to check the alignment of 64-bit atomic operations for (default: all 32-bit architectures)")
 
-	fs.BoolVar(&flags.gosimple.enabled,
-		"simple.enabled", true, "Run gosimple")
-	fs.BoolVar(&flags.gosimple.generated,
-		"simple.generated", false, "Check generated code")
+			fs.BoolVar(&flags.gosimple.enabled,
+				"simple.enabled", true, "Run gosimple")
+			fs.BoolVar(&flags.gosimple.generated,
+				"simple.generated", false, "Check generated code")
+			fs.BoolVar(&flags.gosimple.httpDefaultClient,
+				"simple.http-default-client", false, "Suggest http.DefaultClient instead of zero http.Client values (S1036)")
 
-	fs.BoolVar(&flags.unused.enabled,
-		"unused.enabled", true, "Run unused")
-	fs.BoolVar(&flags.unused.constants,
-		"unused.consts", true, "Report unused constants")
-	fs.BoolVar(&flags.unused.fields,
-		"unused.fields", true, "Report unused fields")
-	fs.BoolVar(&flags.unused.functions,
-		"unused.funcs", true, "Report unused functions and methods")
-	fs.BoolVar(&flags.unused.types,
-		"unused.types", true, "Report unused types")
-	fs.BoolVar(&flags.unused.variables,
-		"unused.vars", true, "Report unused variables")
-	fs.BoolVar(&flags.unused.wholeProgram,
-		"unused.exported", false, "Treat arguments as a program and report unused exported identifiers")
-	fs.BoolVar(&flags.unused.reflection, "unused.reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+			fs.BoolVar(&flags.doc.enabled,
+				"doc.enabled", false, "Run doccheck")
+			fs.BoolVar(&flags.doc.generated,
+				"doc.generated", false, "Check generated code")
 
-	fs.Parse(os.Args[1:])
+			fs.BoolVar(&flags.unused.enabled,
+				"unused.enabled", true, "Run unused")
+			fs.BoolVar(&flags.unused.constants,
+				"unused.consts", true, "Report unused constants")
+			fs.BoolVar(&flags.unused.fields,
+				"unused.fields", true, "Report unused fields")
+			fs.BoolVar(&flags.unused.functions,
+				"unused.funcs", true, "Report unused functions and methods")
+			fs.BoolVar(&flags.unused.types,
+				"unused.types", true, "Report unused types")
+			fs.BoolVar(&flags.unused.variables,
+				"unused.vars", true, "Report unused variables")
+			fs.BoolVar(&flags.unused.wholeProgram,
+				"unused.exported", false, "Treat arguments as a program and report unused exported identifiers")
+			fs.BoolVar(&flags.unused.reflection, "unused.reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+		},
+		Checkers: func() []lint.Checker {
+			var checkers []lint.Checker
 
-	c := &Checker{}
+			if flags.gosimple.enabled {
+				sc := simple.NewChecker()
+				sc.CheckGenerated = flags.gosimple.generated
+				sc.HTTPDefaultClient = flags.gosimple.httpDefaultClient
+				checkers = append(checkers, sc)
+			}
 
-	if flags.gosimple.enabled {
-		sc := simple.NewChecker()
-		sc.CheckGenerated = flags.gosimple.generated
-		c.Checkers = append(c.Checkers, sc)
-	}
+			if flags.staticcheck.enabled {
+				sac := staticcheck.NewChecker()
+				sac.CheckGenerated = flags.staticcheck.generated
+				sac.DisableStyle = !flags.staticcheck.style
+				if flags.staticcheck.archs != "" {
+					sac.Archs = strings.Split(flags.staticcheck.archs, ",")
+				}
+				checkers = append(checkers, sac)
+			}
 
-	if flags.staticcheck.enabled {
-		sac := staticcheck.NewChecker()
-		sac.CheckGenerated = flags.staticcheck.generated
-		c.Checkers = append(c.Checkers, sac)
-	}
+			if flags.doc.enabled {
+				dc := doccheck.NewChecker()
+				dc.CheckGenerated = flags.doc.generated
+				checkers = append(checkers, dc)
+			}
 
-	if flags.unused.enabled {
-		var mode unused.CheckMode
-		if flags.unused.constants {
-			mode |= unused.CheckConstants
-		}
-		if flags.unused.fields {
-			mode |= unused.CheckFields
-		}
-		if flags.unused.functions {
-			mode |= unused.CheckFunctions
-		}
-		if flags.unused.types {
-			mode |= unused.CheckTypes
-		}
-		if flags.unused.variables {
-			mode |= unused.CheckVariables
-		}
-		uc := unused.NewChecker(mode)
-		uc.WholeProgram = flags.unused.wholeProgram
-		uc.ConsiderReflection = flags.unused.reflection
-		c.Checkers = append(c.Checkers, unused.NewLintChecker(uc))
-	}
+			if flags.unused.enabled {
+				var mode unused.CheckMode
+				if flags.unused.constants {
+					mode |= unused.CheckConstants
+				}
+				if flags.unused.fields {
+					mode |= unused.CheckFields
+				}
+				if flags.unused.functions {
+					mode |= unused.CheckFunctions
+				}
+				if flags.unused.types {
+					mode |= unused.CheckTypes
+				}
+				if flags.unused.variables {
+					mode |= unused.CheckVariables
+				}
+				uc := unused.NewChecker(mode)
+				uc.WholeProgram = flags.unused.wholeProgram
+				uc.ConsiderReflection = flags.unused.reflection
+				checkers = append(checkers, unused.NewLintChecker(uc))
+			}
 
-	lintutil.ProcessFlagSet(c, fs)
+			return checkers
+		},
+	})
 }
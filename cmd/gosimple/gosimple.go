@@ -1,18 +1,26 @@
 // gosimple detects code that could be rewritten in a simpler way.
 package main // import "github.com/gm42/go-tools/cmd/gosimple"
 import (
-	"os"
+	"flag"
 
-	"github.com/gm42/go-tools/lint/lintutil"
+	"github.com/gm42/go-tools/internal/driver"
+	"github.com/gm42/go-tools/lint"
 	"github.com/gm42/go-tools/simple"
 )
 
 func main() {
-	fs := lintutil.FlagSet("gosimple")
-	gen := fs.Bool("generated", false, "Check generated code")
-	fs.Parse(os.Args[1:])
-	c := simple.NewChecker()
-	c.CheckGenerated = *gen
-
-	lintutil.ProcessFlagSet(c, fs)
+	var gen, httpDefaultClient bool
+	driver.Main(driver.Tool{
+		Name: "gosimple",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&gen, "generated", false, "Check generated code")
+			fs.BoolVar(&httpDefaultClient, "http-default-client", false, "Suggest http.DefaultClient instead of zero http.Client values (S1036)")
+		},
+		Checkers: func() []lint.Checker {
+			c := simple.NewChecker()
+			c.CheckGenerated = gen
+			c.HTTPDefaultClient = httpDefaultClient
+			return []lint.Checker{c}
+		},
+	})
 }
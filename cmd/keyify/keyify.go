@@ -16,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/gm42/go-tools/internal/edit"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/loader"
@@ -247,15 +248,7 @@ func printComplit(oldlit, newlit *ast.CompositeLit, oldfset, newfset *token.File
 	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
 	_ = cfg.Fprint(buf, newfset, newlit)
 	if fJSON {
-		output := struct {
-			Start       int    `json:"start"`
-			End         int    `json:"end"`
-			Replacement string `json:"replacement"`
-		}{
-			oldfset.Position(oldlit.Pos()).Offset,
-			oldfset.Position(oldlit.End()).Offset,
-			buf.String(),
-		}
+		output := edit.Replace(oldfset, oldlit.Pos(), oldlit.End(), buf.String())
 		_ = json.NewEncoder(os.Stdout).Encode(output)
 	} else {
 		fmt.Println(buf.String())
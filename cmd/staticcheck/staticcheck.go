@@ -3,17 +3,32 @@
 package main // import "github.com/gm42/go-tools/cmd/staticcheck"
 
 import (
-	"os"
+	"flag"
+	"strings"
 
-	"github.com/gm42/go-tools/lint/lintutil"
+	"github.com/gm42/go-tools/internal/driver"
+	"github.com/gm42/go-tools/lint"
 	"github.com/gm42/go-tools/staticcheck"
 )
 
 func main() {
-	fs := lintutil.FlagSet("staticcheck")
-	gen := fs.Bool("generated", false, "Check generated code")
-	fs.Parse(os.Args[1:])
-	c := staticcheck.NewChecker()
-	c.CheckGenerated = *gen
-	lintutil.ProcessFlagSet(c, fs)
+	var gen, style bool
+	var archs string
+	driver.Main(driver.Tool{
+		Name: "staticcheck",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&gen, "generated", false, "Check generated code")
+			fs.BoolVar(&style, "style", true, "Run stylistic checks (ST1???)")
+			fs.StringVar(&archs, "archs", "", "Comma-separated list of `architectures` to check the alignment of 64-bit atomic operations for (default: all 32-bit architectures)")
+		},
+		Checkers: func() []lint.Checker {
+			c := staticcheck.NewChecker()
+			c.CheckGenerated = gen
+			c.DisableStyle = !style
+			if archs != "" {
+				c.Archs = strings.Split(archs, ",")
+			}
+			return []lint.Checker{c}
+		},
+	})
 }
@@ -0,0 +1,239 @@
+// structlayout-check reports structs that could be made smaller by
+// reordering their fields. It exits with a non-zero status if it
+// finds any, making it suitable for use in CI.
+package main // import "github.com/gm42/go-tools/cmd/structlayout-check"
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gm42/go-tools/gcsizes"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+var (
+	fJSON  bool
+	fMin   int64
+	fTests bool
+	fTags  buildutil.TagsFlag
+)
+
+func init() {
+	flag.BoolVar(&fJSON, "json", false, "Format data as JSON")
+	flag.Int64Var(&fMin, "min", 8, "Only report structs that could be at least `N` bytes smaller")
+	flag.BoolVar(&fTests, "tests", false, "Include test files")
+	flag.Var(&fTags, "tags", "List of build tags")
+}
+
+// Report describes a struct that could be made smaller.
+type Report struct {
+	Position string `json:"position"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Optimal  int64  `json:"optimal_size"`
+	// Suggestion is the declaration of the struct with its fields
+	// reordered.
+	Suggestion string `json:"suggestion"`
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if len(flag.Args()) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = fTags
+	conf := loader.Config{
+		Build: &ctx,
+	}
+	paths := gotool.ImportPaths(flag.Args())
+	for _, path := range paths {
+		if fTests {
+			conf.ImportWithTests(path)
+		} else {
+			conf.Import(path)
+		}
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sizes := gcsizes.ForArch(ctx.GOARCH)
+	var reports []Report
+	seen := map[*ast.File]bool{}
+	for _, pkg := range lprog.InitialPackages() {
+		for _, f := range pkg.Files {
+			// With -tests, a package and its test variant share files.
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			reports = append(reports, check(lprog.Fset, &pkg.Info, sizes, f)...)
+		}
+	}
+	if fJSON {
+		emitJSON(reports)
+	} else {
+		emitText(reports)
+	}
+	if len(reports) > 0 {
+		os.Exit(1)
+	}
+}
+
+// field is a single field of a struct declaration. Fields declared
+// together, as in "a, b int", are split up.
+type field struct {
+	name  string
+	expr  ast.Expr
+	tag   *ast.BasicLit
+	v     *types.Var
+	size  int64
+	align int64
+}
+
+func check(fset *token.FileSet, info *types.Info, sizes *gcsizes.Sizes, f *ast.File) []Report {
+	var out []Report
+	ast.Inspect(f, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		expr, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		obj := info.Defs[spec.Name]
+		if obj == nil {
+			return true
+		}
+		T, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return true
+		}
+
+		var fields []field
+		for _, f := range expr.Fields.List {
+			names := []string{""}
+			if len(f.Names) > 0 {
+				names = names[:0]
+				for _, name := range f.Names {
+					names = append(names, name.Name)
+				}
+			}
+			for _, name := range names {
+				fields = append(fields, field{name: name, expr: f.Type, tag: f.Tag})
+			}
+		}
+		if len(fields) != T.NumFields() {
+			return true
+		}
+		for i := range fields {
+			v := T.Field(i)
+			fields[i].v = v
+			fields[i].size = sizes.Sizeof(v.Type())
+			fields[i].align = sizes.Alignof(v.Type())
+		}
+
+		size := sizes.Sizeof(T)
+		sort.Stable(byAlignAndSize(fields))
+		reordered := make([]*types.Var, len(fields))
+		for i, f := range fields {
+			reordered[i] = f.v
+		}
+		optimal := sizes.Sizeof(types.NewStruct(reordered, nil))
+		if size-optimal < fMin {
+			return true
+		}
+		out = append(out, Report{
+			Position:   fset.Position(spec.Pos()).String(),
+			Name:       spec.Name.Name,
+			Size:       size,
+			Optimal:    optimal,
+			Suggestion: suggest(fset, spec.Name.Name, fields),
+		})
+		return true
+	})
+	return out
+}
+
+// suggest renders the declaration of a struct with the given fields.
+func suggest(fset *token.FileSet, name string, fields []field) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package p\n\ntype %s struct {\n", name)
+	for _, f := range fields {
+		buf.WriteString(f.name)
+		if f.name != "" {
+			buf.WriteString(" ")
+		}
+		printer.Fprint(&buf, fset, f.expr)
+		if f.tag != nil {
+			buf.WriteString(" " + f.tag.Value)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		src = buf.Bytes()
+	}
+	return strings.TrimPrefix(string(src), "package p\n\n")
+}
+
+type byAlignAndSize []field
+
+func (s byAlignAndSize) Len() int      { return len(s) }
+func (s byAlignAndSize) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byAlignAndSize) Less(i, j int) bool {
+	// Place zero sized objects before non-zero sized objects.
+	if s[i].size == 0 && s[j].size != 0 {
+		return true
+	}
+	if s[j].size == 0 && s[i].size != 0 {
+		return false
+	}
+
+	// Next, place more tightly aligned objects before less tightly aligned objects.
+	if s[i].align != s[j].align {
+		return s[i].align > s[j].align
+	}
+
+	// Lastly, order by size.
+	return s[i].size > s[j].size
+}
+
+func emitJSON(reports []Report) {
+	if reports == nil {
+		reports = []Report{}
+	}
+	json.NewEncoder(os.Stdout).Encode(reports)
+}
+
+func emitText(reports []Report) {
+	for _, r := range reports {
+		fmt.Printf("%s: struct %s is %d bytes, could be %d bytes by reordering its fields:\n", r.Position, r.Name, r.Size, r.Optimal)
+		for _, line := range strings.Split(strings.TrimSuffix(r.Suggestion, "\n"), "\n") {
+			fmt.Printf("\t%s\n", line)
+		}
+	}
+}
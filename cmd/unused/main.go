@@ -3,10 +3,12 @@
 package main // import "github.com/gm42/go-tools/cmd/unused"
 
 import (
+	"flag"
 	"log"
 	"os"
 
-	"github.com/gm42/go-tools/lint/lintutil"
+	"github.com/gm42/go-tools/internal/driver"
+	"github.com/gm42/go-tools/lint"
 	"github.com/gm42/go-tools/unused"
 )
 
@@ -40,35 +42,38 @@ func newChecker(mode unused.CheckMode) *unused.Checker {
 func main() {
 	log.SetFlags(0)
 
-	fs := lintutil.FlagSet("unused")
-	fs.BoolVar(&fConstants, "consts", true, "Report unused constants")
-	fs.BoolVar(&fFields, "fields", true, "Report unused fields")
-	fs.BoolVar(&fFunctions, "funcs", true, "Report unused functions and methods")
-	fs.BoolVar(&fTypes, "types", true, "Report unused types")
-	fs.BoolVar(&fVariables, "vars", true, "Report unused variables")
-	fs.StringVar(&fDebug, "debug", "", "Write a debug graph to `file`. Existing files will be overwritten.")
-	fs.BoolVar(&fWholeProgram, "exported", false, "Treat arguments as a program and report unused exported identifiers")
-	fs.BoolVar(&fReflection, "reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
-	fs.Parse(os.Args[1:])
+	driver.Main(driver.Tool{
+		Name: "unused",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&fConstants, "consts", true, "Report unused constants")
+			fs.BoolVar(&fFields, "fields", true, "Report unused fields")
+			fs.BoolVar(&fFunctions, "funcs", true, "Report unused functions and methods")
+			fs.BoolVar(&fTypes, "types", true, "Report unused types")
+			fs.BoolVar(&fVariables, "vars", true, "Report unused variables")
+			fs.StringVar(&fDebug, "debug", "", "Write a debug graph to `file`. Existing files will be overwritten.")
+			fs.BoolVar(&fWholeProgram, "exported", false, "Treat arguments as a program and report unused exported identifiers")
+			fs.BoolVar(&fReflection, "reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+		},
+		Checkers: func() []lint.Checker {
+			var mode unused.CheckMode
+			if fConstants {
+				mode |= unused.CheckConstants
+			}
+			if fFields {
+				mode |= unused.CheckFields
+			}
+			if fFunctions {
+				mode |= unused.CheckFunctions
+			}
+			if fTypes {
+				mode |= unused.CheckTypes
+			}
+			if fVariables {
+				mode |= unused.CheckVariables
+			}
 
-	var mode unused.CheckMode
-	if fConstants {
-		mode |= unused.CheckConstants
-	}
-	if fFields {
-		mode |= unused.CheckFields
-	}
-	if fFunctions {
-		mode |= unused.CheckFunctions
-	}
-	if fTypes {
-		mode |= unused.CheckTypes
-	}
-	if fVariables {
-		mode |= unused.CheckVariables
-	}
-
-	checker := newChecker(mode)
-	l := unused.NewLintChecker(checker)
-	lintutil.ProcessFlagSet(l, fs)
+			checker := newChecker(mode)
+			return []lint.Checker{unused.NewLintChecker(checker)}
+		},
+	})
 }
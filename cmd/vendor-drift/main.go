@@ -0,0 +1,251 @@
+// vendor-drift compares vendored packages against their upstream
+// copies in GOPATH and reports local modifications.
+package main // import "github.com/gm42/go-tools/cmd/vendor-drift"
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gm42/go-tools/internal/edit"
+)
+
+var (
+	fJSON    bool
+	fDiff    bool
+	fRemoved bool
+)
+
+func init() {
+	flag.BoolVar(&fJSON, "json", false, "Print a JSON summary instead of text")
+	flag.BoolVar(&fDiff, "d", false, "Print diffs of modified and added files")
+	flag.BoolVar(&fRemoved, "removed", false, "Report files that exist upstream but not in the vendored copy, which vendoring tools often remove on purpose")
+}
+
+// Package is a vendored package and how it differs from upstream.
+type Package struct {
+	// ImportPath is the import path of the package without the vendor
+	// prefix.
+	ImportPath string `json:"import_path"`
+	Dir        string `json:"dir"`
+	// UpstreamDir is the directory of the upstream copy, or empty if
+	// there is none.
+	UpstreamDir string   `json:"upstream_dir,omitempty"`
+	Modified    []string `json:"modified,omitempty"`
+	Added       []string `json:"added,omitempty"`
+	Removed     []string `json:"removed,omitempty"`
+}
+
+func (pkg Package) drifted() bool {
+	return pkg.UpstreamDir == "" || len(pkg.Modified) > 0 || len(pkg.Added) > 0 || len(pkg.Removed) > 0
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	var pkgs []Package
+	for _, root := range roots {
+		vendors, err := findVendorDirs(root)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, vendor := range vendors {
+			ps, err := compareVendorDir(&build.Default, vendor)
+			if err != nil {
+				log.Fatal(err)
+			}
+			pkgs = append(pkgs, ps...)
+		}
+	}
+
+	drifted := pkgs[:0]
+	for _, pkg := range pkgs {
+		if pkg.drifted() {
+			drifted = append(drifted, pkg)
+		}
+	}
+	if fJSON {
+		emitJSON(drifted)
+	} else {
+		emitText(drifted)
+	}
+	if len(drifted) > 0 {
+		os.Exit(1)
+	}
+}
+
+// findVendorDirs returns all vendor directories below root.
+func findVendorDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		name := fi.Name()
+		if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata") {
+			return filepath.SkipDir
+		}
+		if name == "vendor" {
+			dirs = append(dirs, path)
+			// Nested vendor directories of vendored packages are
+			// compared as part of their package.
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// compareVendorDir compares all packages in a vendor directory with
+// the packages of the same import paths in GOPATH.
+func compareVendorDir(ctx *build.Context, vendor string) ([]Package, error) {
+	var pkgs []Package
+	err := filepath.Walk(vendor, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() || path == vendor {
+			return nil
+		}
+		files, err := regularFiles(path)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(vendor, path)
+		if err != nil {
+			return err
+		}
+		pkg := Package{
+			ImportPath: filepath.ToSlash(rel),
+			Dir:        path,
+		}
+		// Importing without a source directory ignores vendor
+		// directories.
+		bpkg, err := ctx.Import(pkg.ImportPath, "", build.FindOnly)
+		if err != nil || bpkg.Goroot {
+			pkgs = append(pkgs, pkg)
+			return nil
+		}
+		pkg.UpstreamDir = bpkg.Dir
+		upstream, err := regularFiles(bpkg.Dir)
+		if err != nil {
+			return err
+		}
+		for name := range files {
+			if _, ok := upstream[name]; !ok {
+				pkg.Added = append(pkg.Added, name)
+				continue
+			}
+			same, err := sameContents(filepath.Join(path, name), filepath.Join(bpkg.Dir, name))
+			if err != nil {
+				return err
+			}
+			if !same {
+				pkg.Modified = append(pkg.Modified, name)
+			}
+		}
+		if fRemoved {
+			for name := range upstream {
+				if _, ok := files[name]; !ok {
+					pkg.Removed = append(pkg.Removed, name)
+				}
+			}
+		}
+		sort.Strings(pkg.Modified)
+		sort.Strings(pkg.Added)
+		sort.Strings(pkg.Removed)
+		pkgs = append(pkgs, pkg)
+		return nil
+	})
+	return pkgs, err
+}
+
+// regularFiles returns the names of the regular files in dir.
+func regularFiles(dir string) (map[string]bool, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]bool{}
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() {
+			files[fi.Name()] = true
+		}
+	}
+	return files, nil
+}
+
+func sameContents(a, b string) (bool, error) {
+	ba, err := ioutil.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	bb, err := ioutil.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ba, bb), nil
+}
+
+func emitJSON(pkgs []Package) {
+	if pkgs == nil {
+		pkgs = []Package{}
+	}
+	json.NewEncoder(os.Stdout).Encode(pkgs)
+}
+
+func emitText(pkgs []Package) {
+	for _, pkg := range pkgs {
+		if pkg.UpstreamDir == "" {
+			fmt.Printf("%s: no upstream copy of %s found\n", pkg.Dir, pkg.ImportPath)
+			continue
+		}
+		for _, name := range pkg.Modified {
+			fmt.Printf("%s: modified\n", filepath.Join(pkg.Dir, name))
+			if fDiff {
+				printDiff(filepath.Join(pkg.UpstreamDir, name), filepath.Join(pkg.Dir, name))
+			}
+		}
+		for _, name := range pkg.Added {
+			fmt.Printf("%s: added\n", filepath.Join(pkg.Dir, name))
+			if fDiff {
+				printDiff(os.DevNull, filepath.Join(pkg.Dir, name))
+			}
+		}
+		for _, name := range pkg.Removed {
+			fmt.Printf("%s: removed\n", filepath.Join(pkg.Dir, name))
+		}
+	}
+}
+
+// printDiff prints a unified diff between two files.
+func printDiff(old, new string) {
+	a, err := ioutil.ReadFile(old)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(new)
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Stdout.Write(edit.Diff(old, new, a, b))
+}
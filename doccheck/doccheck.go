@@ -0,0 +1,204 @@
+// Package doccheck contains a linter that checks the presence and
+// form of documentation comments.
+package doccheck // import "github.com/gm42/go-tools/doccheck"
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gm42/go-tools/lint"
+)
+
+type Checker struct {
+	CheckGenerated bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+func (c *Checker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"DOC1000": c.CheckMissingDoc,
+		"DOC1001": c.CheckDocPrefix,
+		"DOC1002": c.CheckPackageComment,
+	}
+}
+
+func (c *Checker) Docs() map[string]*lint.Documentation {
+	return map[string]*lint.Documentation{
+		"DOC1000": {Title: "Exported identifier without a doc comment"},
+		"DOC1001": {Title: "Doc comment doesn't begin with the identifier's name, or with \"Package <name>\""},
+		"DOC1002": {Title: "Package without a package comment"},
+	}
+}
+
+func (c *Checker) Init(prog *lint.Program) {}
+
+func (c *Checker) filterFiles(j *lint.Job, files []*ast.File) []*ast.File {
+	var out []*ast.File
+	for _, f := range files {
+		if !c.CheckGenerated && lint.IsGenerated(f) {
+			continue
+		}
+		if strings.HasSuffix(j.Program.SSA.Fset.File(f.Pos()).Name(), "_test.go") {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// documented is a declaration of exported identifiers that should be
+// documented.
+type documented struct {
+	node ast.Node
+	name string
+	kind string
+	doc  *ast.CommentGroup
+	// group is set if the doc comment belongs to a parenthesized
+	// group of declarations, in which case it needn't start with the
+	// identifier's name.
+	group bool
+}
+
+func isExportedRecv(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return true
+	}
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	ident, ok := typ.(*ast.Ident)
+	return ok && ident.IsExported()
+}
+
+func exportedDecls(f *ast.File) []documented {
+	var out []documented
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() || !isExportedRecv(decl) {
+				continue
+			}
+			kind := "function"
+			if decl.Recv != nil {
+				kind = "method"
+			}
+			out = append(out, documented{decl.Name, decl.Name.Name, kind, decl.Doc, false})
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			grouped := decl.Lparen.IsValid()
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if !spec.Name.IsExported() {
+						continue
+					}
+					doc, group := spec.Doc, false
+					if doc == nil {
+						doc, group = decl.Doc, grouped
+					}
+					out = append(out, documented{spec.Name, spec.Name.Name, "type", doc, group})
+				case *ast.ValueSpec:
+					kind := "var"
+					if decl.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range spec.Names {
+						if !name.IsExported() {
+							continue
+						}
+						doc, group := spec.Doc, false
+						if doc == nil {
+							doc, group = decl.Doc, grouped
+						}
+						if len(spec.Names) > 1 {
+							group = true
+						}
+						out = append(out, documented{name, name.Name, kind, doc, group})
+						// Only report the first name of a spec.
+						break
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// hasNamePrefix reports whether the comment text starts with the
+// identifier name as a whole word.
+func hasNamePrefix(text, name string) bool {
+	if !strings.HasPrefix(text, name) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text[len(name):])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+func (c *Checker) CheckMissingDoc(j *lint.Job) {
+	for _, f := range c.filterFiles(j, j.Program.Files) {
+		if f.Name.Name == "main" {
+			continue
+		}
+		for _, d := range exportedDecls(f) {
+			if d.doc == nil {
+				j.Errorf(d.node, "exported %s %s should have a doc comment", d.kind, d.name)
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckDocPrefix(j *lint.Job) {
+	for _, f := range c.filterFiles(j, j.Program.Files) {
+		if f.Doc != nil && f.Name.Name != "main" {
+			prefix := "Package " + f.Name.Name + " "
+			if !strings.HasPrefix(f.Doc.Text(), prefix) {
+				j.Errorf(f.Doc, "package comment should be of the form %q", prefix+"...")
+			}
+		}
+		for _, d := range exportedDecls(f) {
+			if d.doc == nil || d.group {
+				continue
+			}
+			text := d.doc.Text()
+			if d.kind == "type" {
+				for _, article := range []string{"A ", "An ", "The "} {
+					if strings.HasPrefix(text, article) {
+						text = text[len(article):]
+						break
+					}
+				}
+			}
+			if !hasNamePrefix(text, d.name) {
+				j.Errorf(d.doc, "comment on exported %s %s should be of the form %q", d.kind, d.name, d.name+" ...")
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckPackageComment(j *lint.Job) {
+	for _, pkg := range j.Program.Packages {
+		files := c.filterFiles(j, pkg.Info.Files)
+		if len(files) == 0 {
+			continue
+		}
+		documented := false
+		for _, f := range files {
+			if f.Doc != nil {
+				documented = true
+				break
+			}
+		}
+		if !documented {
+			j.Errorf(files[0].Name, "package %s should have a package comment", files[0].Name.Name)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Package pkg is documented.
+package pkg
+
+// Fn1 is documented.
+func Fn1() {}
+
+// Fn2.
+func Fn2() {}
+
+// This is Fn3.
+func Fn3() {} // MATCH:10 /comment on exported function Fn3 should be of the form "Fn3 \.\.\."/
+
+// Fn4x does things.
+func Fn4() {} // MATCH:13 /function Fn4 should be/
+
+// A T1 is documented.
+type T1 struct{}
+
+// T2 is documented.
+type T2 struct{}
+
+// Type T3 is documented.
+type T3 struct{} // MATCH:22 /type T3 should be/
+
+// Variables.
+var (
+	V1 int
+	V2 int
+)
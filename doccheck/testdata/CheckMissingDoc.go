@@ -0,0 +1,33 @@
+// Package pkg is documented.
+package pkg
+
+func Fn1() {} // MATCH "exported function Fn1 should have a doc comment"
+
+// Fn2 is documented.
+func Fn2() {}
+
+func fn3() {}
+
+type T1 struct{} // MATCH "exported type T1 should have a doc comment"
+
+type t2 struct{}
+
+func (T1) Method1() {} // MATCH "exported method Method1 should have a doc comment"
+
+func (*t2) Method2() {}
+
+// Constants.
+const (
+	C1 = 1
+	C2 = 2
+)
+
+const C3 = 3 // MATCH "exported const C3 should have a doc comment"
+
+var V1, v2 int // MATCH "exported var V1 should have a doc comment"
+
+var (
+	// V3 is documented.
+	V3 int
+	V4 int // MATCH "exported var V4 should have a doc comment"
+)
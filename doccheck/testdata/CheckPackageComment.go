@@ -0,0 +1 @@
+package pkg // MATCH "package pkg should have a package comment"
@@ -0,0 +1,2 @@
+// pkg is documented.
+package pkg // MATCH:1 /package comment should be of the form "Package pkg ..."/
@@ -1,29 +1,91 @@
 package errcheck
 
 import (
+	"go/ast"
 	"go/types"
 
 	"github.com/gm42/go-tools/functions"
 	"github.com/gm42/go-tools/lint"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/ssa"
 )
 
 type Checker struct {
+	// Blank causes errors that are explicitly assigned to the blank
+	// identifier to be reported, too.
+	Blank bool
+
+	// Swallow enables ERR1001, which reports errors that a function
+	// receives but never returns, wraps, logs or compares.
+	Swallow bool
+	// Sinks are functions, named like "log.Printf" or
+	// "(*log.Logger).Printf", that are considered to handle all
+	// errors passed to them.
+	Sinks map[string]bool
+	// Stores determines whether storing an error in a struct field,
+	// a slice or array element or a map counts as handling it.
+	// Errors whose only uses are unhandled stores are reported by
+	// ERR1000 and ERR1001.
+	Stores StorePolicy
+
 	funcDescs *functions.Descriptions
+	stores    *storeTracker
+}
+
+// DefaultSinks are the functions that Sinks is initialized with.
+var DefaultSinks = []string{
+	"log.Fatal", "log.Fatalf", "log.Fatalln",
+	"log.Panic", "log.Panicf", "log.Panicln",
+	"log.Print", "log.Printf", "log.Println",
+	"(*log.Logger).Fatal", "(*log.Logger).Fatalf", "(*log.Logger).Fatalln",
+	"(*log.Logger).Panic", "(*log.Logger).Panicf", "(*log.Logger).Panicln",
+	"(*log.Logger).Print", "(*log.Logger).Printf", "(*log.Logger).Println",
 }
 
 func NewChecker() *Checker {
-	return &Checker{}
+	c := &Checker{Sinks: map[string]bool{}}
+	for _, sink := range DefaultSinks {
+		c.Sinks[sink] = true
+	}
+	return c
 }
 
 func (c *Checker) Funcs() map[string]lint.Func {
-	return map[string]lint.Func{
+	fns := map[string]lint.Func{
 		"ERR1000": c.CheckErrcheck,
+		"ERR1001": nil,
+	}
+	if c.Swallow {
+		fns["ERR1001"] = c.CheckSwallowed
 	}
+	return fns
+}
+
+func (c *Checker) Docs() map[string]*lint.Documentation {
+	return docs
+}
+
+var docs = map[string]*lint.Documentation{
+	"ERR1000": {Title: "Unchecked error"},
+	"ERR1001": {
+		Title: "Error is silently dropped",
+		Text: `A function receives an error, as a parameter or as the result of a
+call, but never returns, wraps, logs or compares it. This catches
+helpers that drop errors even though their callers did check them.
+Errors passed to other functions are followed into these functions.`,
+	},
+}
+
+func init() {
+	lint.Register(
+		&lint.Check{ID: "ERR1000", Documentation: *docs["ERR1000"], SSA: true},
+		&lint.Check{ID: "ERR1001", Documentation: *docs["ERR1001"], SSA: true},
+	)
 }
 
 func (c *Checker) Init(prog *lint.Program) {
 	c.funcDescs = functions.NewDescriptions(prog.SSA)
+	c.stores = newStoreTracker(c.Stores, prog.AllFunctions)
 }
 
 func (c *Checker) CheckErrcheck(j *lint.Job) {
@@ -39,6 +101,7 @@ func (c *Checker) CheckErrcheck(j *lint.Job) {
 				case "fmt.Print", "fmt.Println", "fmt.Printf":
 					continue
 				}
+				stored := false
 				isRecover := false
 				if builtin, ok := ssacall.Common().Value.(*ssa.Builtin); ok {
 					isRecover = ok && builtin.Name() == "recover"
@@ -47,9 +110,16 @@ func (c *Checker) CheckErrcheck(j *lint.Job) {
 				switch ins := ins.(type) {
 				case ssa.Value:
 					refs := ins.Referrers()
-					if refs == nil || len(lint.FilterDebug(*refs)) != 0 {
+					if refs == nil {
 						continue
 					}
+					if len(lint.FilterDebug(*refs)) != 0 {
+						call, ok := ins.(*ssa.Call)
+						if !ok || !c.stores.storedOnly(errorValue(call), map[ssa.Value]bool{}) {
+							continue
+						}
+						stored = true
+					}
 				case ssa.Instruction:
 					// will be a 'go' or 'defer', neither of which has usable return values
 				default:
@@ -114,10 +184,221 @@ func (c *Checker) CheckErrcheck(j *lint.Job) {
 						continue
 					}
 				}
-				j.Errorf(ins, "unchecked error")
+				kind := callKind(j, ssacall)
+				if stored {
+					kind = "stored"
+				}
+				if kind == "blank" && !c.Blank {
+					continue
+				}
+				p := j.Errorf(ins, "unchecked error")
+				p.Details = map[string]string{
+					"callee": callee(ssacall.Common()),
+					"kind":   kind,
+				}
+			}
+		}
+	}
+}
+
+func isErrorType(T types.Type) bool {
+	return types.TypeString(T, nil) == "error"
+}
+
+func (c *Checker) CheckSwallowed(j *lint.Job) {
+	// params caches whether functions handle their parameters.
+	// Parameters that are being analysed are assumed to be handled,
+	// which ends recursion.
+	params := map[*ssa.Parameter]bool{}
+	var handled func(v ssa.Value, seen map[ssa.Value]bool) bool
+	handledParam := func(param *ssa.Parameter) bool {
+		if h, ok := params[param]; ok {
+			return h
+		}
+		params[param] = true
+		h := handled(param, map[ssa.Value]bool{})
+		params[param] = h
+		return h
+	}
+	handledByCall := func(call *ssa.CallCommon, v ssa.Value) bool {
+		if call.IsInvoke() || c.Sinks[lint.CallName(call)] {
+			return true
+		}
+		fn := call.StaticCallee()
+		if fn == nil || fn.Blocks == nil || len(fn.Params) != len(call.Args) {
+			return true
+		}
+		for i, arg := range call.Args {
+			if arg == v && handledParam(fn.Params[i]) {
+				return true
+			}
+		}
+		return false
+	}
+	handled = func(v ssa.Value, seen map[ssa.Value]bool) bool {
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+		refs := v.Referrers()
+		if refs == nil {
+			return false
+		}
+		for _, ref := range *refs {
+			switch ref := ref.(type) {
+			case *ssa.DebugRef:
+			case *ssa.Phi:
+				if handled(ref, seen) {
+					return true
+				}
+			case *ssa.ChangeInterface:
+				if handled(ref, seen) {
+					return true
+				}
+			case *ssa.MakeInterface:
+				if handled(ref, seen) {
+					return true
+				}
+			case ssa.CallInstruction:
+				if handledByCall(ref.Common(), v) {
+					return true
+				}
+			case *ssa.Store:
+				if ref.Val != v || c.stores.locationRead(ref.Addr) {
+					return true
+				}
+			case *ssa.MapUpdate:
+				if ref.Value != v || c.stores.contentsRead(ref.Map) {
+					return true
+				}
+			default:
+				// Returns, comparisons, type assertions, sends,
+				// panics and everything else either
+				// handle the error or let it escape our analysis.
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, fn := range j.Program.InitialFunctions {
+		if fn.Blocks == nil || isEmptyFunc(fn) {
+			continue
+		}
+		for _, param := range fn.Params {
+			if param.Name() == "_" || param.Object() == nil || !isErrorType(param.Type()) {
+				continue
+			}
+			if !handledParam(param) {
+				j.Errorf(param, "error parameter %s is never returned, wrapped, logged or compared", param.Name())
+			}
+		}
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				v := errorValue(call)
+				// Errors without any uses, or that are only stored,
+				// are the domain of ERR1000.
+				if v == nil || v.Referrers() == nil || len(lint.FilterDebug(*v.Referrers())) == 0 ||
+					c.stores.storedOnly(v, map[ssa.Value]bool{}) {
+					continue
+				}
+				if !handled(v, map[ssa.Value]bool{}) {
+					name := callee(call.Common())
+					if name == "" {
+						name = "this call"
+					}
+					j.Errorf(call, "error returned by %s is never returned, wrapped, logged or compared", name)
+				}
+			}
+		}
+	}
+}
+
+// errorValue returns the error returned by call, or nil if it
+// doesn't return one or the error is never extracted from the
+// results.
+func errorValue(call *ssa.Call) ssa.Value {
+	res := call.Common().Signature().Results()
+	switch {
+	case res.Len() == 1 && isErrorType(res.At(0).Type()):
+		return call
+	case res.Len() > 1 && isErrorType(res.At(res.Len()-1).Type()):
+		for _, ref := range *call.Referrers() {
+			if ex, ok := ref.(*ssa.Extract); ok && ex.Index == res.Len()-1 {
+				return ex
+			}
+		}
+	}
+	return nil
+}
+
+// isEmptyFunc reports whether fn has an empty body. Such functions
+// are usually deliberate no-op implementations of interfaces.
+func isEmptyFunc(fn *ssa.Function) bool {
+	switch syntax := fn.Syntax().(type) {
+	case *ast.FuncDecl:
+		return syntax.Body != nil && len(syntax.Body.List) == 0
+	case *ast.FuncLit:
+		return len(syntax.Body.List) == 0
+	}
+	return false
+}
+
+// callee returns the fully qualified name of the called function or
+// method, or the empty string for calls of function values.
+func callee(call *ssa.CallCommon) string {
+	if call.IsInvoke() {
+		return call.Method.FullName()
+	}
+	return lint.CallName(call)
+}
+
+// callKind classifies how the result of a call was ignored: "defer"
+// and "go" for deferred calls and goroutines, "blank" for
+// assignments to the blank identifier, "expression" for expression
+// statements and "unused" for values that were assigned to a
+// variable but never used. Errors that are only stored in
+// locations that the store policy considers unhandled are of kind
+// "stored".
+func callKind(j *lint.Job, call ssa.CallInstruction) string {
+	switch call.(type) {
+	case *ssa.Defer:
+		return "defer"
+	case *ssa.Go:
+		return "go"
+	}
+	f := j.File(call)
+	if f == nil {
+		return "unused"
+	}
+	path, _ := astutil.PathEnclosingInterval(f, call.Pos(), call.Pos())
+	for _, node := range path {
+		switch stmt := node.(type) {
+		case *ast.ExprStmt:
+			return "expression"
+		case *ast.AssignStmt:
+			// The error is either the only or the last result.
+			lhs := stmt.Lhs[len(stmt.Lhs)-1]
+			if len(stmt.Lhs) == len(stmt.Rhs) {
+				for i, rhs := range stmt.Rhs {
+					if rhs.Pos() <= call.Pos() && call.Pos() < rhs.End() {
+						lhs = stmt.Lhs[i]
+					}
+				}
+			}
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "_" {
+				return "blank"
 			}
+			return "unused"
+		case ast.Stmt:
+			return "unused"
 		}
 	}
+	return "unused"
 }
 
 func isReadOnlyFile(val ssa.Value, seen map[ssa.Value]bool) bool {
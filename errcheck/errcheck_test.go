@@ -8,5 +8,7 @@ import (
 
 func TestAll(t *testing.T) {
 	c := NewChecker()
+	c.Swallow = true
+	c.Stores = StoresRead
 	testutil.TestAll(t, c, "")
 }
@@ -0,0 +1,222 @@
+package errcheck
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"github.com/gm42/go-tools/lint"
+	"golang.org/x/tools/go/ssa"
+)
+
+// A StorePolicy determines whether storing an error in a struct
+// field, a slice or array element or a map counts as handling it.
+type StorePolicy int
+
+const (
+	// StoresHandled considers all stored errors handled.
+	StoresHandled StorePolicy = iota
+	// StoresRead considers stored errors handled if the location
+	// they are stored in is read somewhere in the program. Exported
+	// fields, and slices and maps that other code has access to,
+	// are assumed to be read.
+	StoresRead
+	// StoresUnhandled considers stored errors unhandled, unless
+	// they end up being returned or otherwise used, for example by
+	// appending them to a local slice that is returned.
+	StoresUnhandled
+)
+
+var storePolicies = []string{
+	StoresHandled:   "handled",
+	StoresRead:      "read",
+	StoresUnhandled: "unhandled",
+}
+
+func (p StorePolicy) String() string {
+	if p < 0 || int(p) >= len(storePolicies) {
+		return fmt.Sprintf("StorePolicy(%d)", int(p))
+	}
+	return storePolicies[p]
+}
+
+// Set implements flag.Value.
+func (p *StorePolicy) Set(s string) error {
+	for i, name := range storePolicies {
+		if name == s {
+			*p = StorePolicy(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown store policy %q, must be one of handled, read or unhandled", s)
+}
+
+// storeTracker decides, according to a StorePolicy, whether errors
+// stored in fields, slices and maps are read again.
+type storeTracker struct {
+	policy StorePolicy
+	// fields are the unexported fields that are read somewhere in
+	// the program.
+	fields map[*types.Var]bool
+}
+
+func newStoreTracker(policy StorePolicy, fns []*ssa.Function) *storeTracker {
+	t := &storeTracker{policy: policy, fields: map[*types.Var]bool{}}
+	if policy != StoresRead {
+		return t
+	}
+	for _, fn := range fns {
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				switch ins := ins.(type) {
+				case *ssa.Field:
+					t.fields[field(ins.X.Type(), ins.Field)] = true
+				case *ssa.FieldAddr:
+					if !t.onlyWritten(ins, map[ssa.Value]bool{}) {
+						t.fields[field(ins.X.Type(), ins.Field)] = true
+					}
+				}
+			}
+		}
+	}
+	return t
+}
+
+// field returns the ith field of the struct T, or of the struct T
+// points to.
+func field(T types.Type, i int) *types.Var {
+	if ptr, ok := T.Underlying().(*types.Pointer); ok {
+		T = ptr.Elem()
+	}
+	return T.Underlying().(*types.Struct).Field(i)
+}
+
+func (t *storeTracker) fieldRead(field *types.Var) bool {
+	switch t.policy {
+	case StoresHandled:
+		return true
+	case StoresUnhandled:
+		return false
+	}
+	return field.Exported() || t.fields[field]
+}
+
+// locationRead reports whether the value stored at addr is read.
+func (t *storeTracker) locationRead(addr ssa.Value) bool {
+	if t.policy == StoresHandled {
+		return true
+	}
+	switch addr := addr.(type) {
+	case *ssa.FieldAddr:
+		return t.fieldRead(field(addr.X.Type(), addr.Field))
+	case *ssa.IndexAddr:
+		return t.contentsRead(addr.X)
+	}
+	// Globals and variables captured by closures are beyond the
+	// scope of the policy.
+	return true
+}
+
+// contentsRead reports whether the elements of v, a slice, a map or
+// a pointer to an array, are read.
+func (t *storeTracker) contentsRead(v ssa.Value) bool {
+	if t.policy == StoresHandled {
+		return true
+	}
+	switch v := v.(type) {
+	case *ssa.Alloc, *ssa.MakeSlice, *ssa.MakeMap:
+		return !t.onlyWritten(v, map[ssa.Value]bool{})
+	case *ssa.Slice:
+		return t.contentsRead(v.X) || !t.onlyWritten(v, map[ssa.Value]bool{})
+	case *ssa.Call:
+		if isAppend(v.Common()) {
+			return t.contentsRead(v.Common().Args[0]) || !t.onlyWritten(v, map[ssa.Value]bool{})
+		}
+	case *ssa.UnOp:
+		if v.Op == token.MUL {
+			return t.locationRead(v.X)
+		}
+	case *ssa.Field:
+		return t.fieldRead(field(v.X.Type(), v.Field))
+	}
+	// Parameters, globals and results of calls may be shared with
+	// code that reads them.
+	return t.policy != StoresUnhandled
+}
+
+// onlyWritten reports whether v, or anything derived from it, is
+// only ever written to or stored in locations that aren't read.
+func (t *storeTracker) onlyWritten(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		return true
+	}
+	seen[v] = true
+	refs := v.Referrers()
+	if refs == nil {
+		return true
+	}
+	for _, ref := range lint.FilterDebug(*refs) {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			if ref.Addr != v && t.locationRead(ref.Addr) {
+				return false
+			}
+		case *ssa.MapUpdate:
+			if ref.Map != v && t.contentsRead(ref.Map) {
+				return false
+			}
+		case *ssa.IndexAddr, *ssa.FieldAddr, *ssa.Slice, *ssa.Phi:
+			if !t.onlyWritten(ref.(ssa.Value), seen) {
+				return false
+			}
+		case *ssa.UnOp:
+			if ref.Op != token.MUL || !t.onlyWritten(ref, seen) {
+				return false
+			}
+		case *ssa.Call:
+			if !isAppend(ref.Common()) || !t.onlyWritten(ref, seen) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// storedOnly reports whether the error v is used for nothing but
+// being stored in locations that aren't read.
+func (t *storeTracker) storedOnly(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if v == nil || t.policy == StoresHandled || seen[v] {
+		return false
+	}
+	seen[v] = true
+	refs := v.Referrers()
+	if refs == nil || len(lint.FilterDebug(*refs)) == 0 {
+		return false
+	}
+	for _, ref := range lint.FilterDebug(*refs) {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			if ref.Val != v || t.locationRead(ref.Addr) {
+				return false
+			}
+		case *ssa.MapUpdate:
+			if ref.Value != v || t.contentsRead(ref.Map) {
+				return false
+			}
+		case *ssa.MakeInterface, *ssa.ChangeInterface:
+			if !t.storedOnly(ref.(ssa.Value), seen) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isAppend(call *ssa.CallCommon) bool {
+	builtin, ok := call.Value.(*ssa.Builtin)
+	return ok && builtin.Name() == "append"
+}
@@ -154,3 +154,30 @@ func main() {
 	h := md5.New()
 	h.Write(nil)
 }
+
+type Errs struct {
+	err  error
+	errs []error
+	m    map[string]error
+	Err  error
+
+	read error
+}
+
+func (e *Errs) Read() error { return e.read }
+
+func stores(e *Errs, errsIn []error) []error {
+	e.err = a()                  // MATCH /unchecked error/
+	e.errs = append(e.errs, a()) // MATCH /unchecked error/
+	e.m["a"] = a()               // MATCH /unchecked error/
+	e.Err = a()
+	e.read = a()
+	errsIn[0] = a()
+
+	m := map[string]error{}
+	m["a"] = a() // MATCH /unchecked error/
+
+	var local []error
+	local = append(local, a())
+	return local
+}
@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+var errSentinel = errors.New("sentinel")
+
+func get() (int, error) { return 0, nil }
+
+func drop(err error) { // MATCH /error parameter err is never returned, wrapped, logged or compared/
+	fmt.Println("something went wrong")
+}
+
+func dropVia(err error) { // MATCH /error parameter err is never/
+	drop(err)
+}
+
+func dropResult() int {
+	n, err := get() // MATCH /error returned by pkg.get is never returned/
+	drop(err)
+	return n
+}
+
+func ret(err error) error { return err }
+
+func wrap(err error) error { return fmt.Errorf("doing things: %v", err) }
+
+func logged(err error) { log.Println(err) }
+
+func compared(err error) bool { return err == errSentinel }
+
+func viaHelper(err error) error { return ret(err) }
+
+func method(err error) string { return err.Error() }
+
+func noop(error) {}
+
+func blank(_ error) { fmt.Println() }
+
+func empty(err error) {}
+
+func handledResult() (int, error) {
+	n, err := get()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func recursive(err error, n int) error {
+	if n == 0 {
+		return err
+	}
+	return recursive(err, n-1)
+}
+
+type Holder struct {
+	err  error
+	read error
+}
+
+func (h *Holder) Err() error { return h.read }
+
+func storeParam(h *Holder, err error) { // MATCH /error parameter err is never/
+	h.err = err
+}
+
+func storeParamRead(h *Holder, err error) {
+	h.read = err
+}
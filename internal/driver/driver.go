@@ -0,0 +1,224 @@
+// Package driver implements the command line driver shared by the
+// linters in cmd/.
+//
+// A linter binary describes itself with a Tool and hands control to
+// Main, which takes care of flag parsing, loading packages, running
+// the checkers and printing problems. Features that affect all
+// linters belong here, not in the individual commands.
+package driver // import "github.com/gm42/go-tools/internal/driver"
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gm42/go-tools/lint"
+	"github.com/gm42/go-tools/lint/lintutil"
+)
+
+// A Tool describes a linter command.
+type Tool struct {
+	// Name is the name of the command, as used in usage messages.
+	Name string
+
+	// Flags registers tool-specific flags. It may be nil.
+	Flags func(fs *flag.FlagSet)
+
+	// Checkers returns the checkers to run. It is called after the
+	// command line has been parsed, so it may depend on the values
+	// of flags registered by Flags.
+	Checkers func() []lint.Checker
+}
+
+// Main runs tool with the command line arguments in os.Args and
+// exits the process.
+func Main(tool Tool) {
+	fs := lintutil.FlagSet(tool.Name)
+	fs.Bool("list-checks", false, "List the available checks and exit")
+	fs.String("explain", "", "Print the documentation of the check with the given `ID` and exit")
+	if tool.Flags != nil {
+		tool.Flags(fs)
+	}
+	fs.Parse(os.Args[1:])
+
+	c := NewMultiChecker(tool.Checkers()...)
+	if fs.Lookup("list-checks").Value.(flag.Getter).Get().(bool) {
+		format := fs.Lookup("f").Value.(flag.Getter).Get().(string)
+		urlBase := fs.Lookup("docs-url").Value.(flag.Getter).Get().(string)
+		if err := listChecks(os.Stdout, c, format, urlBase); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if id := fs.Lookup("explain").Value.(flag.Getter).Get().(string); id != "" {
+		if err := explain(os.Stdout, c, id); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	lintutil.ProcessFlagSet(c, fs)
+}
+
+// CheckInfo is the machine-readable description of a check, as
+// emitted by -list-checks.
+type CheckInfo struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Text  string `json:"text,omitempty"`
+	// Severity is "error" or "warning". It is informational only;
+	// all problems cause a non-zero exit status.
+	Severity string `json:"severity"`
+	// MinGoVersion is the oldest Go version, such as "1.8", that the
+	// check applies to, if it doesn't apply to all of them.
+	MinGoVersion string `json:"min_go_version,omitempty"`
+	// SSA reports whether the check needs the SSA form of the
+	// program. Checks that aren't registered are assumed to.
+	SSA bool `json:"ssa"`
+	// Autofix is always false; no check offers automatic fixes.
+	Autofix bool `json:"autofix"`
+	// URL links to the documentation of the check. It is only set
+	// if a base URL was provided with -docs-url.
+	URL string `json:"url,omitempty"`
+}
+
+// Checks returns descriptions of all checks of c, sorted by ID.
+func Checks(c lint.Checker) []CheckInfo {
+	docs := checkerDocs(c)
+	var out []CheckInfo
+	for id, fn := range c.Funcs() {
+		if fn == nil {
+			// Disabled check
+			continue
+		}
+		out = append(out, checkInfo(id, docs))
+	}
+	sort.Sort(byID(out))
+	return out
+}
+
+func checkerDocs(c lint.Checker) map[string]*lint.Documentation {
+	if d, ok := c.(lint.Documenter); ok {
+		return d.Docs()
+	}
+	return nil
+}
+
+// checkInfo describes the check with the given ID, preferring the
+// check registry over the documentation of the checker.
+func checkInfo(id string, docs map[string]*lint.Documentation) CheckInfo {
+	info := CheckInfo{ID: id, Severity: lint.SeverityError.String(), SSA: true}
+	if doc := docs[id]; doc != nil {
+		info.Title = doc.Title
+		info.Text = doc.Text
+	}
+	if check := lint.LookupCheck(id); check != nil {
+		info.Title = check.Title
+		info.Text = check.Text
+		info.Severity = check.Severity.String()
+		info.SSA = check.SSA
+		if check.MinGoVersion > 0 {
+			info.MinGoVersion = fmt.Sprintf("1.%d", check.MinGoVersion)
+		}
+	}
+	return info
+}
+
+type byID []CheckInfo
+
+func (s byID) Len() int           { return len(s) }
+func (s byID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+func (s byID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func listChecks(w io.Writer, c lint.Checker, format string, urlBase string) error {
+	checks := Checks(c)
+	for i := range checks {
+		checks[i].URL = lintutil.CheckURL(urlBase, checks[i].ID)
+	}
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, check := range checks {
+			if check.URL != "" {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", check.ID, check.Title, check.URL)
+			} else {
+				fmt.Fprintf(tw, "%s\t%s\n", check.ID, check.Title)
+			}
+		}
+		return tw.Flush()
+	case "json":
+		if checks == nil {
+			checks = []CheckInfo{}
+		}
+		return json.NewEncoder(w).Encode(checks)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// explain prints the documentation of the check with the given ID.
+// Checks that are disabled by flags can be explained, too.
+func explain(w io.Writer, c lint.Checker, id string) error {
+	if _, ok := c.Funcs()[id]; !ok {
+		return fmt.Errorf("unknown check %q", id)
+	}
+	info := checkInfo(id, checkerDocs(c))
+	fmt.Fprintf(w, "%s: %s\n", info.ID, info.Title)
+	if info.Text != "" {
+		fmt.Fprintf(w, "\n%s\n", info.Text)
+	}
+	fmt.Fprintf(w, "\nSeverity: %s\n", info.Severity)
+	if info.MinGoVersion != "" {
+		fmt.Fprintf(w, "Applies to Go %s and later\n", info.MinGoVersion)
+	}
+	return nil
+}
+
+// MultiChecker combines several checkers into one.
+type MultiChecker struct {
+	Checkers []lint.Checker
+}
+
+// NewMultiChecker returns a checker that runs all of checkers. If
+// only a single checker is provided, it is returned as is.
+func NewMultiChecker(checkers ...lint.Checker) lint.Checker {
+	if len(checkers) == 1 {
+		return checkers[0]
+	}
+	return &MultiChecker{Checkers: checkers}
+}
+
+func (c *MultiChecker) Init(prog *lint.Program) {
+	for _, cc := range c.Checkers {
+		cc.Init(prog)
+	}
+}
+
+func (c *MultiChecker) Docs() map[string]*lint.Documentation {
+	docs := map[string]*lint.Documentation{}
+	for _, cc := range c.Checkers {
+		d, ok := cc.(lint.Documenter)
+		if !ok {
+			continue
+		}
+		for k, v := range d.Docs() {
+			docs[k] = v
+		}
+	}
+	return docs
+}
+
+func (c *MultiChecker) Funcs() map[string]lint.Func {
+	fns := map[string]lint.Func{}
+	for _, cc := range c.Checkers {
+		for k, v := range cc.Funcs() {
+			fns[k] = v
+		}
+	}
+	return fns
+}
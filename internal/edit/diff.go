@@ -0,0 +1,175 @@
+package edit
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// context is the number of unchanged lines that a hunk includes
+// before and after changes, like the default of diff -u.
+const context = 3
+
+// Diff returns a unified diff of the change from old to new, with
+// oldName and newName in its header, or nil if there is none.
+func Diff(oldName, newName string, old, new []byte) []byte {
+	a, b := splitLines(old), splitLines(new)
+	ops := diffLines(a, b)
+	var buf bytes.Buffer
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Find the end of the hunk, which includes all changes that
+		// are less than two contexts apart.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		i = end
+		end += context
+		if end > len(ops) {
+			end = len(ops)
+		}
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&buf, a, b, ops[start:end])
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func writeHunk(buf *bytes.Buffer, a, b []string, ops []op) {
+	oldStart, newStart := ops[0].a, ops[0].b
+	oldLines, newLines := 0, 0
+	for _, o := range ops {
+		switch o.kind {
+		case ' ':
+			oldLines++
+			newLines++
+		case '-':
+			oldLines++
+		case '+':
+			newLines++
+		}
+	}
+	// Empty ranges start at the line before them.
+	if oldLines > 0 {
+		oldStart++
+	}
+	if newLines > 0 {
+		newStart++
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldLines, newStart, newLines)
+	for _, o := range ops {
+		var line string
+		if o.kind == '+' {
+			line = b[o.b]
+		} else {
+			line = a[o.a]
+		}
+		buf.WriteByte(o.kind)
+		buf.WriteString(line)
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits src into lines, keeping the line endings.
+func splitLines(src []byte) []string {
+	var lines []string
+	for len(src) > 0 {
+		i := bytes.IndexByte(src, '\n') + 1
+		if i == 0 {
+			i = len(src)
+		}
+		lines = append(lines, string(src[:i]))
+		src = src[i:]
+	}
+	return lines
+}
+
+// An op is a step in turning one list of lines into another: ' '
+// keeps line a of the old list, which equals line b of the new
+// one, '-' deletes line a of the old list and '+' inserts line b of
+// the new list. Both indices are set for all ops, denoting the
+// position in the respective list.
+type op struct {
+	kind byte
+	a, b int
+}
+
+// diffLines computes the shortest edit script from a to b using
+// Myers' algorithm.
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	max := n + m
+	off := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{' ', x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, op{'+', x, prevY})
+			} else {
+				ops = append(ops, op{'-', prevX, y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,159 @@
+// Package edit implements text edits based on byte offsets, for
+// tools that rewrite source code.
+//
+// Edits can be applied to the contents of files, and turned into
+// unified diffs or the text edits of the language server protocol.
+package edit // import "github.com/gm42/go-tools/internal/edit"
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"unicode/utf8"
+)
+
+// An Edit replaces the bytes from Start up to End with New. Start
+// and End are byte offsets; an edit with Start == End inserts New.
+//
+// The JSON encoding of edits is the one keyify uses with -json.
+type Edit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	New   string `json:"replacement"`
+}
+
+// Replace returns an edit that replaces the source code between
+// the positions start and end with new.
+func Replace(fset *token.FileSet, start, end token.Pos, new string) Edit {
+	return Edit{
+		Start: fset.Position(start).Offset,
+		End:   fset.Position(end).Offset,
+		New:   new,
+	}
+}
+
+// OverlapError is returned for edits that overlap each other. Two
+// insertions at the same offset overlap, too, because their order
+// would be ambiguous.
+type OverlapError struct {
+	A, B Edit
+}
+
+func (err *OverlapError) Error() string {
+	return fmt.Sprintf("edit of bytes %d-%d overlaps edit of bytes %d-%d", err.A.Start, err.A.End, err.B.Start, err.B.End)
+}
+
+type byStart []Edit
+
+func (s byStart) Len() int      { return len(s) }
+func (s byStart) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byStart) Less(i, j int) bool {
+	if s[i].Start != s[j].Start {
+		return s[i].Start < s[j].Start
+	}
+	return s[i].End < s[j].End
+}
+
+// Sort sorts edits by their offsets.
+func Sort(edits []Edit) {
+	sort.Stable(byStart(edits))
+}
+
+// Check checks that edits are valid for a file of size bytes: their
+// offsets must be in range and they must not overlap. edits must be
+// sorted.
+func Check(edits []Edit, size int) error {
+	for i, e := range edits {
+		if e.Start < 0 || e.Start > e.End || e.End > size {
+			return fmt.Errorf("edit of bytes %d-%d is out of range of %d bytes", e.Start, e.End, size)
+		}
+		if i > 0 {
+			prev := edits[i-1]
+			if e.Start < prev.End || (e.Start == prev.Start && e.Start == e.End && prev.Start == prev.End) {
+				return &OverlapError{prev, e}
+			}
+		}
+	}
+	return nil
+}
+
+// Apply returns the result of applying edits to src. The edits may
+// be in any order, but must not overlap.
+func Apply(src []byte, edits []Edit) ([]byte, error) {
+	edits = append([]Edit(nil), edits...)
+	Sort(edits)
+	if err := Check(edits, len(src)); err != nil {
+		return nil, err
+	}
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		out = append(out, src[last:e.Start]...)
+		out = append(out, e.New...)
+		last = e.End
+	}
+	return append(out, src[last:]...), nil
+}
+
+// A Position is a position in a file, as used by the language
+// server protocol: both line and character are zero-based, and
+// characters are counted in UTF-16 code units.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// A Range is the range of a TextEdit.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// A TextEdit is an edit as used by the language server protocol.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// TextEdits converts edits of src to the text edits of the language
+// server protocol.
+func TextEdits(src []byte, edits []Edit) ([]TextEdit, error) {
+	sorted := append([]Edit(nil), edits...)
+	Sort(sorted)
+	if err := Check(sorted, len(src)); err != nil {
+		return nil, err
+	}
+	out := make([]TextEdit, len(edits))
+	for i, e := range edits {
+		out[i] = TextEdit{
+			Range: Range{
+				Start: position(src, e.Start),
+				End:   position(src, e.End),
+			},
+			NewText: e.New,
+		}
+	}
+	return out, nil
+}
+
+// position returns the position of the byte offset in src.
+func position(src []byte, offset int) Position {
+	var pos Position
+	for i := 0; i < offset; {
+		if src[i] == '\n' {
+			pos.Line++
+			pos.Character = 0
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(src[i:])
+		if r >= 0x10000 {
+			// Encoded as a surrogate pair in UTF-16
+			pos.Character += 2
+		} else {
+			pos.Character++
+		}
+		i += size
+	}
+	return pos
+}
@@ -0,0 +1,79 @@
+package edit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	src := []byte("hello, world")
+	edits := []Edit{
+		{Start: 7, End: 12, New: "gopher"},
+		{Start: 0, End: 5, New: "goodbye"},
+		{Start: 5, End: 5, New: "!"},
+	}
+	got, err := Apply(src, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "goodbye!, gopher"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := [][]Edit{
+		{{Start: 0, End: 5}, {Start: 4, End: 6}},
+		{{Start: 3, End: 3, New: "a"}, {Start: 3, End: 3, New: "b"}},
+		{{Start: 5, End: 4}},
+		{{Start: 0, End: 13}},
+	}
+	for _, edits := range tests {
+		if _, err := Apply([]byte("hello, world"), edits); err == nil {
+			t.Errorf("Apply(%v) succeeded, want error", edits)
+		}
+	}
+}
+
+func TestTextEdits(t *testing.T) {
+	src := []byte("a\n\U0001F600é x\n")
+	got, err := TextEdits(src, []Edit{{Start: 9, End: 10, New: "y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TextEdit{{
+		Range:   Range{Start: Position{Line: 1, Character: 4}, End: Position{Line: 1, Character: 5}},
+		NewText: "y",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n")
+	new := []byte("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17")
+	want := `--- a.go.orig
++++ a.go
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -14,3 +14,4 @@
+ 14
+ 15
+ 16
++17
+\ No newline at end of file
+`
+	if got := string(Diff("a.go.orig", "a.go", old, new)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff("a", "b", old, old); got != nil {
+		t.Errorf("got %q for identical files, want nil", got)
+	}
+}
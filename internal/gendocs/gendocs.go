@@ -0,0 +1,74 @@
+// gendocs generates the Docs method of a checker from the check
+// documentation in a cmd/*/docs/checks directory.
+//
+// Each file in that directory is named after a check and contains
+// the check's title on the first line, optionally followed by a blank
+// line and a longer description.
+//
+// Usage:
+//
+//	gendocs -pkg staticcheck -o docs.go ../cmd/staticcheck/docs/checks
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	pkg := flag.String("pkg", "", "Name of the generated package")
+	out := flag.String("o", "docs.go", "Output `file`")
+	flag.Parse()
+	if *pkg == "" || flag.NArg() != 1 {
+		flag.Usage()
+		log.Fatal("need -pkg and a directory")
+	}
+	dir := flag.Arg(0)
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by gendocs from %s. DO NOT EDIT.\n\n", filepath.ToSlash(dir))
+	fmt.Fprintf(buf, "package %s\n\n", *pkg)
+	fmt.Fprintf(buf, "import \"github.com/gm42/go-tools/lint\"\n\n")
+	fmt.Fprintf(buf, "func (*Checker) Docs() map[string]*lint.Documentation {\n")
+	fmt.Fprintf(buf, "\treturn docs\n}\n\n")
+	fmt.Fprintf(buf, "var docs = map[string]*lint.Documentation{\n")
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			log.Fatal(err)
+		}
+		s := strings.TrimSpace(string(b))
+		title, text := s, ""
+		if i := strings.Index(s, "\n"); i != -1 {
+			title, text = s[:i], strings.TrimSpace(s[i+1:])
+		}
+		fmt.Fprintf(buf, "\t%q: {\n\t\tTitle: %q,\n", fi.Name(), title)
+		if text != "" {
+			fmt.Fprintf(buf, "\t\tText: %q,\n", text)
+		}
+		fmt.Fprintf(buf, "\t},\n")
+	}
+	fmt.Fprintf(buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/constant"
 	"go/printer"
 	"go/token"
@@ -20,6 +21,9 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/gm42/go-tools/gcsizes"
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/loader"
@@ -32,6 +36,7 @@ type Job struct {
 
 	check    string
 	problems []Problem
+	duration time.Duration
 }
 
 type Ignore struct {
@@ -49,9 +54,15 @@ type Program struct {
 	Files            []*ast.File
 	Info             *types.Info
 	GoVersion        int
+	// Sizes describes the sizes and alignments of types on the
+	// target platform.
+	Sizes types.Sizes
+	// Build is the build context the program was loaded with.
+	Build *build.Context
 
 	tokenFileMap map[*token.File]*ast.File
 	astFileMap   map[*ast.File]*Pkg
+	dirPkgMap    map[string]*Pkg
 }
 
 type Func func(*Job)
@@ -60,6 +71,17 @@ type Func func(*Job)
 type Problem struct {
 	Position token.Pos // position in source file
 	Text     string    // the prose that describes the problem
+	Check    string    // the ID of the check that reported the problem
+
+	// Details holds optional, check-specific information about the
+	// problem, for use by machine-readable output formats.
+	Details map[string]string
+
+	// Function is the name of the function declaration containing
+	// the problem, in the form path/to/pkg.Func or
+	// path/to/pkg.Type.Method, or the empty string if the problem
+	// isn't inside a function.
+	Function string
 }
 
 func (p *Problem) String() string {
@@ -71,25 +93,76 @@ type Checker interface {
 	Funcs() map[string]Func
 }
 
+// Documentation describes a check.
+type Documentation struct {
+	// Title is a one-line summary of the check.
+	Title string
+	// Text is an optional, longer explanation.
+	Text string
+}
+
+// A Documenter is a Checker that can describe its checks. Docs maps
+// check IDs to their documentation.
+type Documenter interface {
+	Docs() map[string]*Documentation
+}
+
 // A Linter lints Go source code.
 type Linter struct {
 	Checker   Checker
 	Ignores   []Ignore
 	GoVersion int
+
+	// Partial contains the import paths of packages that were loaded
+	// without type-checking their function bodies. No SSA is built
+	// for the functions of these packages.
+	Partial map[string]bool
+
+	// Sizes describes the sizes and alignments of types on the
+	// target platform. If nil, the sizes used by gc for
+	// build.Default.GOARCH are assumed.
+	Sizes types.Sizes
+
+	// Build is the build context the program was loaded with. If
+	// nil, build.Default is assumed.
+	Build *build.Context
+
+	// MaxTime, if not zero, limits the time spent running checks.
+	// Checks that haven't finished by then are skipped: their
+	// problems are discarded and their IDs recorded in Skipped.
+	// Checks can't be interrupted, so they keep running in the
+	// background until they're done.
+	MaxTime time.Duration
+
+	// Timings is set by Lint to the wall time spent building SSA
+	// (under the key "ssa") and running each finished check.
+	Timings map[string]time.Duration
+	// Skipped is set by Lint to the sorted IDs of checks that were
+	// skipped because of MaxTime.
+	Skipped []string
 }
 
 func (l *Linter) ignore(j *Job, p Problem) bool {
 	tf := j.Program.SSA.Fset.File(p.Position)
 	f := j.Program.tokenFileMap[tf]
-	pkg := j.Program.astFileMap[f].Pkg
+	pkg := j.Program.astFileMap[f]
+	if pkg == nil {
+		// Problems may be reported in files that are part of the
+		// package directory but weren't loaded, for example because
+		// of build constraints.
+		pkg = j.Program.dirPkgMap[filepath.Dir(tf.Name())]
+		if pkg == nil {
+			return false
+		}
+	}
 
 	for _, ig := range l.Ignores {
-		pkgpath := pkg.Path()
+		pkgpath := pkg.Pkg.Path()
 		if strings.HasSuffix(pkgpath, "_test") {
 			pkgpath = pkgpath[:len(pkgpath)-len("_test")]
 		}
 		name := filepath.Join(pkgpath, filepath.Base(tf.Name()))
-		if m, _ := filepath.Match(ig.Pattern, name); !m {
+		if !matchGlob(ig.Pattern, name) {
 			continue
 		}
 		for _, c := range ig.Checks {
@@ -101,6 +174,88 @@ func (l *Linter) ignore(j *Job, p Problem) bool {
 	return false
 }
 
+// disableChecks disables the checks in funcs that don't apply to the
+// targeted Go version, according to the registry, and the checks
+// that are ignored in all files, so that no work is done for them.
+func (l *Linter) disableChecks(funcs map[string]Func) {
+	for id := range funcs {
+		if c := LookupCheck(id); c != nil && c.MinGoVersion > l.GoVersion {
+			funcs[id] = nil
+		}
+	}
+	for _, ig := range l.Ignores {
+		if ig.Pattern != "**" {
+			continue
+		}
+		for id := range funcs {
+			for _, c := range ig.Checks {
+				if m, _ := filepath.Match(c, id); m {
+					funcs[id] = nil
+				}
+			}
+		}
+	}
+}
+
+// matchGlob reports whether name matches the shell pattern. In
+// addition to the syntax supported by filepath.Match, a path element
+// consisting of ** matches zero or more path elements, so that
+// "gen/**" matches all files below gen and "**/*_mock.go" matches
+// files ending in _mock.go in any package.
+func matchGlob(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if m, _ := filepath.Match(pattern[0], name[0]); !m {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
+
+// enclosingFunction returns the qualified name of the function
+// declaration that contains pos, or the empty string.
+func (prog *Program) enclosingFunction(pos token.Pos) string {
+	f := prog.tokenFileMap[prog.SSA.Fset.File(pos)]
+	pkg, ok := prog.astFileMap[f]
+	if !ok {
+		return ""
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || pos < fn.Pos() || pos >= fn.End() {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) == 1 {
+			T := prog.Info.TypeOf(fn.Recv.List[0].Type)
+			if ptr, ok := T.(*types.Pointer); ok {
+				T = ptr.Elem()
+			}
+			if named, ok := T.(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+			}
+		}
+		return pkg.Pkg.Path() + "." + name
+	}
+	return ""
+}
+
 func (j *Job) File(node Positioner) *ast.File {
 	return j.Program.tokenFileMap[j.Program.SSA.Fset.File(node.Pos())]
 }
@@ -136,8 +291,38 @@ func (ps byPosition) Swap(i int, j int) {
 }
 
 func (l *Linter) Lint(lprog *loader.Program) []Problem {
+	l.Timings = map[string]time.Duration{}
+	l.Skipped = nil
+	funcs := l.Checker.Funcs()
+	l.disableChecks(funcs)
+	needSSA := false
+	for id, fn := range funcs {
+		if fn == nil {
+			continue
+		}
+		// Checks that aren't registered may use SSA.
+		if c := LookupCheck(id); c == nil || c.SSA {
+			needSSA = true
+			break
+		}
+	}
+
+	t := time.Now()
 	ssaprog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
-	ssaprog.Build()
+	switch {
+	case !needSSA:
+		// Without building, the packages only have their members,
+		// which is all that checks working on the AST need.
+	case len(l.Partial) == 0:
+		ssaprog.Build()
+	default:
+		for _, pkg := range ssaprog.AllPackages() {
+			if !l.Partial[pkg.Pkg.Path()] {
+				pkg.Build()
+			}
+		}
+	}
+	l.Timings["ssa"] = time.Since(t)
 	pkgMap := map[*ssa.Package]*Pkg{}
 	var pkgs []*Pkg
 	for _, pkginfo := range lprog.InitialPackages() {
@@ -155,20 +340,31 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 		Packages:     pkgs,
 		Info:         &types.Info{},
 		GoVersion:    l.GoVersion,
+		Sizes:        l.Sizes,
+		Build:        l.Build,
 		tokenFileMap: map[*token.File]*ast.File{},
 		astFileMap:   map[*ast.File]*Pkg{},
+		dirPkgMap:    map[string]*Pkg{},
+	}
+	if prog.Sizes == nil {
+		prog.Sizes = gcsizes.ForArch(build.Default.GOARCH)
+	}
+	if prog.Build == nil {
+		prog.Build = &build.Default
 	}
 	initial := map[*types.Package]struct{}{}
 	for _, pkg := range pkgs {
 		initial[pkg.Info.Pkg] = struct{}{}
 	}
-	for fn := range ssautil.AllFunctions(ssaprog) {
-		if fn.Pkg == nil {
-			continue
-		}
-		prog.AllFunctions = append(prog.AllFunctions, fn)
-		if _, ok := initial[fn.Pkg.Pkg]; ok {
-			prog.InitialFunctions = append(prog.InitialFunctions, fn)
+	if needSSA {
+		for fn := range ssautil.AllFunctions(ssaprog) {
+			if fn.Pkg == nil {
+				continue
+			}
+			prog.AllFunctions = append(prog.AllFunctions, fn)
+			if _, ok := initial[fn.Pkg.Pkg]; ok {
+				prog.InitialFunctions = append(prog.InitialFunctions, fn)
+			}
 		}
 	}
 	for _, pkg := range pkgs {
@@ -179,6 +375,7 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 			tf := lprog.Fset.File(f.Pos())
 			prog.tokenFileMap[tf] = f
 			prog.astFileMap[f] = pkgMap[ssapkg]
+			prog.dirPkgMap[filepath.Dir(tf.Name())] = pkgMap[ssapkg]
 		}
 	}
 
@@ -226,7 +423,6 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 	}
 	l.Checker.Init(prog)
 
-	funcs := l.Checker.Funcs()
 	var keys []string
 	for k := range funcs {
 		keys = append(keys, k)
@@ -241,24 +437,48 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 		}
 		jobs = append(jobs, j)
 	}
-	wg := &sync.WaitGroup{}
+	done := make(chan *Job, len(jobs))
 	for _, j := range jobs {
-		wg.Add(1)
 		go func(j *Job) {
-			defer wg.Done()
+			defer func() { done <- j }()
 			fn := funcs[j.check]
 			if fn == nil {
 				return
 			}
+			t := time.Now()
 			fn(j)
+			j.duration = time.Since(t)
 		}(j)
 	}
-	wg.Wait()
+	var timeout <-chan time.Time
+	if l.MaxTime > 0 {
+		timer := time.NewTimer(l.MaxTime)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	finished := map[*Job]bool{}
+wait:
+	for range jobs {
+		select {
+		case j := <-done:
+			finished[j] = true
+		case <-timeout:
+			break wait
+		}
+	}
 
 	var out []Problem
 	for _, j := range jobs {
+		if !finished[j] {
+			l.Skipped = append(l.Skipped, j.check)
+			continue
+		}
+		if funcs[j.check] != nil {
+			l.Timings[j.check] = j.duration
+		}
 		for _, p := range j.problems {
 			if !l.ignore(j, p) {
+				p.Function = prog.enclosingFunction(p.Position)
 				out = append(out, p)
 			}
 		}
@@ -313,6 +533,7 @@ func (j *Job) Errorf(n Positioner, format string, args ...interface{}) *Problem
 	problem := Problem{
 		Position: n.Pos(),
 		Text:     fmt.Sprintf(format, args...) + fmt.Sprintf(" (%s)", j.check),
+		Check:    j.check,
 	}
 	j.problems = append(j.problems, problem)
 	return &j.problems[len(j.problems)-1]
@@ -0,0 +1,28 @@
+package lint
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"os/exec/*_test.go", "os/exec/exec_test.go", true},
+		{"os/exec/*_test.go", "os/exec/exec.go", false},
+		{"os/*", "os/exec/exec.go", false},
+		{"**/*_mock.go", "foo_mock.go", true},
+		{"**/*_mock.go", "example.com/pkg/foo_mock.go", true},
+		{"**/*_mock.go", "example.com/pkg/foo.go", false},
+		{"example.com/gen/**", "example.com/gen/a.go", true},
+		{"example.com/gen/**", "example.com/gen/sub/a.go", true},
+		{"example.com/gen/**", "example.com/other/a.go", false},
+		{"example.com/**/internal/*.go", "example.com/a/b/internal/x.go", true},
+		{"example.com/**/internal/*.go", "example.com/internal/x.go", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
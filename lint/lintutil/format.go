@@ -0,0 +1,209 @@
+package lintutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/gm42/go-tools/lint"
+)
+
+type formatter interface {
+	Format(p lint.Problem, pos token.Position)
+}
+
+// CheckURL returns the URL of the documentation of check, given the
+// base URL of all check documentation. It returns the empty string
+// if base is empty.
+func CheckURL(base, check string) string {
+	if base == "" || check == "" {
+		return ""
+	}
+	return base + check
+}
+
+type textFormatter struct {
+	w       io.Writer
+	urlBase string
+	// function causes the enclosing function to be printed in front
+	// of the message.
+	function bool
+	// color causes the output to be colorized with ANSI escape
+	// sequences, and each problem to be followed by the offending
+	// line of source code and a caret pointing at the problem.
+	color  bool
+	source sourceLines
+}
+
+func (f textFormatter) Format(p lint.Problem, pos token.Position) {
+	text := p.Text
+	if url := CheckURL(f.urlBase, p.Check); url != "" {
+		text = strings.TrimSuffix(text, " ("+p.Check+")") + fmt.Sprintf(" (%s, %s)", p.Check, url)
+	}
+	if f.function && p.Function != "" {
+		text = p.Function + ": " + text
+	}
+	if !f.color {
+		fmt.Fprintf(f.w, "%v: %s\n", relativePositionString(pos), text)
+		return
+	}
+
+	style := severityStyle(p.Check)
+	if i := strings.LastIndex(text, " ("+p.Check); p.Check != "" && i != -1 {
+		text = text[:i] + " " + style + text[i+1:] + styleReset
+	}
+	fmt.Fprintf(f.w, "%s%v:%s %s\n", styleBold, relativePositionString(pos), styleReset, text)
+	line, ok := f.source.line(pos.Filename, pos.Line)
+	if !ok || pos.Column < 1 || pos.Column > len(line)+1 {
+		return
+	}
+	// Keep the tabs of the indentation so that the caret lines up
+	// with the column, no matter the width of tabs.
+	var pad []rune
+	for _, r := range line[:pos.Column-1] {
+		if r == '\t' {
+			pad = append(pad, '\t')
+		} else {
+			pad = append(pad, ' ')
+		}
+	}
+	fmt.Fprintf(f.w, "%s\n%s%s^%s\n", line, string(pad), style, styleReset)
+}
+
+const (
+	styleReset  = "\x1b[0m"
+	styleBold   = "\x1b[1m"
+	styleRed    = "\x1b[1;31m"
+	styleYellow = "\x1b[1;33m"
+)
+
+// severityStyle returns the style of problems of a check. Problems
+// of checks that find bugs, such as those of staticcheck and
+// errcheck-ng, are red; simplifications, style issues and the like
+// are yellow. Checks that aren't registered are told apart by their
+// IDs.
+func severityStyle(check string) string {
+	if c := lint.LookupCheck(check); c != nil {
+		if c.Severity == lint.SeverityError {
+			return styleRed
+		}
+		return styleYellow
+	}
+	if strings.HasPrefix(check, "SA") || strings.HasPrefix(check, "ERR") {
+		return styleRed
+	}
+	return styleYellow
+}
+
+// sourceLines caches the lines of source files, keyed by file name.
+type sourceLines map[string][]string
+
+// line returns the nth line of the named file.
+func (sl sourceLines) line(filename string, n int) (string, bool) {
+	if sl == nil || filename == "" {
+		return "", false
+	}
+	lines, ok := sl[filename]
+	if !ok {
+		b, err := ioutil.ReadFile(filename)
+		if err == nil {
+			sc := bufio.NewScanner(bytes.NewReader(b))
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+		}
+		sl[filename] = lines
+	}
+	if n < 1 || n > len(lines) {
+		return "", false
+	}
+	return lines[n-1], true
+}
+
+// lines returns the lines from through to of the named file, joined
+// by newlines, and the number of the first line. The range is
+// clamped to the lines of the file, but has to include at least
+// one of them.
+func (sl sourceLines) lines(filename string, from, to int) (int, string, bool) {
+	if from < 1 {
+		from = 1
+	}
+	var out []string
+	for n := from; n <= to; n++ {
+		line, ok := sl.line(filename, n)
+		if !ok {
+			break
+		}
+		out = append(out, line)
+	}
+	if len(out) == 0 {
+		return 0, "", false
+	}
+	return from, strings.Join(out, "\n") + "\n", true
+}
+
+// isTerminal reports whether f is a terminal that understands ANSI
+// escape sequences.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
+
+// jsonFormatter emits one JSON object per problem and line.
+type jsonFormatter struct {
+	w       io.Writer
+	urlBase string
+	// snippets causes each problem to include the offending line of
+	// source code and context lines before and after it.
+	snippets bool
+	context  int
+	source   sourceLines
+}
+
+func (f jsonFormatter) Format(p lint.Problem, pos token.Position) {
+	type location struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+	type snippet struct {
+		// StartLine is the line number of the first line of Text.
+		StartLine int    `json:"start_line"`
+		Text      string `json:"text"`
+	}
+	jp := struct {
+		Code     string            `json:"code"`
+		Location location          `json:"location"`
+		Message  string            `json:"message"`
+		Details  map[string]string `json:"details,omitempty"`
+		URL      string            `json:"url,omitempty"`
+		Function string            `json:"function,omitempty"`
+		Snippet  *snippet          `json:"snippet,omitempty"`
+	}{
+		Code: p.Check,
+		Location: location{
+			File:   pos.Filename,
+			Line:   pos.Line,
+			Column: pos.Column,
+		},
+		Message:  strings.TrimSuffix(p.Text, " ("+p.Check+")"),
+		Details:  p.Details,
+		URL:      CheckURL(f.urlBase, p.Check),
+		Function: p.Function,
+	}
+	if f.snippets {
+		if start, text, ok := f.source.lines(pos.Filename, pos.Line-f.context, pos.Line+f.context); ok {
+			jp.Snippet = &snippet{StartLine: start, Text: text}
+		}
+	}
+	_ = json.NewEncoder(f.w).Encode(jp)
+}
@@ -14,14 +14,23 @@ import (
 	"go/build"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/gm42/go-tools/gcsizes"
 	"github.com/gm42/go-tools/lint"
 
 	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/loader"
 )
 
@@ -38,10 +47,17 @@ func usage(name string, flags *flag.FlagSet) func() {
 }
 
 type runner struct {
-	checker lint.Checker
-	tags    []string
-	ignores []lint.Ignore
-	version int
+	checker      lint.Checker
+	tags         []string
+	ignores      []lint.Ignore
+	version      int
+	tests        bool
+	memoryBudget uint64
+	maxTime      time.Duration
+	goarch       string
+	timings      io.Writer
+	loadTime     time.Duration
+	ctx          *build.Context
 }
 
 func (runner runner) resolveRelative(importPaths []string) (goFiles bool, err error) {
@@ -115,8 +131,18 @@ func FlagSet(name string) *flag.FlagSet {
 	flags.Usage = usage(name, flags)
 	flags.Float64("min_confidence", 0, "Deprecated; use -ignore instead")
 	flags.String("tags", "", "List of `build tags`")
-	flags.String("ignore", "", "Space separated list of checks to ignore, in the following format: 'import/path/file.go:Check1,Check2,...' Both the import path and file name sections support globbing, e.g. 'os/exec/*_test.go'")
+	flags.String("ignore", "", "Space separated list of checks to ignore, in the following format: 'import/path/file.go:Check1,Check2,...' Both the import path and file name sections support globbing, e.g. 'os/exec/*_test.go', and '**' matches any number of path elements, e.g. 'example.com/gen/**' or '**/*_mock.go'")
 	flags.Bool("tests", true, "Include tests")
+	flags.String("f", "text", "Output `format` (valid choices are 'text' and 'json')")
+	flags.String("docs-url", "", "Base `URL` of the documentation of checks. If set, problems link to the base followed by the ID of their check, e.g. 'https://example.com/checks#'")
+	flags.String("color", "auto", "Colorize text output and show the offending source lines: 'always', 'never', or 'auto' to colorize it when writing to a terminal")
+	flags.Bool("show-function", false, "Print the function containing each problem in front of its message; JSON output always includes it")
+	flags.Bool("snippets", false, "Include the source code of each problem in JSON output, so that it can be quoted without access to the files")
+	flags.Uint("snippet-context", 2, "Number of `lines` before and after each problem that -snippets includes")
+	flags.Bool("stream", false, "Lint one package at a time and print problems as soon as a package is done. Checks that need the whole program, such as unused -exported, only see one package at a time")
+	flags.Uint64("memory-budget", 0, "Heap size in `MiB` above which dependencies are loaded without function bodies; 0 means no limit")
+	flags.Duration("max-time", 0, "Maximum `duration` of running checks on the packages, or with -stream on each package, after which unfinished checks are skipped; 0 means no limit")
+	flags.Bool("debug.timing", false, "Print the time spent loading packages, building SSA and running each check to stderr")
 
 	tags := build.Default.ReleaseTags
 	v := tags[len(tags)-1][2:]
@@ -126,6 +152,7 @@ func FlagSet(name string) *flag.FlagSet {
 	}
 
 	flags.Var(version, "go", "Target Go `version` in the format '1.x'")
+	flags.String("arch", build.Default.GOARCH, "Target `architecture`, which determines the files that are checked and the sizes of types")
 	return flags
 }
 
@@ -134,22 +161,73 @@ func ProcessFlagSet(c lint.Checker, fs *flag.FlagSet) {
 	ignore := fs.Lookup("ignore").Value.(flag.Getter).Get().(string)
 	tests := fs.Lookup("tests").Value.(flag.Getter).Get().(bool)
 	version := fs.Lookup("go").Value.(flag.Getter).Get().(int)
+	format := fs.Lookup("f").Value.(flag.Getter).Get().(string)
+	stream := fs.Lookup("stream").Value.(flag.Getter).Get().(bool)
+	budget := fs.Lookup("memory-budget").Value.(flag.Getter).Get().(uint64)
+	urlBase := fs.Lookup("docs-url").Value.(flag.Getter).Get().(string)
+	showFunction := fs.Lookup("show-function").Value.(flag.Getter).Get().(bool)
+	color := fs.Lookup("color").Value.(flag.Getter).Get().(string)
+	snippets := fs.Lookup("snippets").Value.(flag.Getter).Get().(bool)
+	snippetContext := fs.Lookup("snippet-context").Value.(flag.Getter).Get().(uint)
+	maxTime := fs.Lookup("max-time").Value.(flag.Getter).Get().(time.Duration)
+	timing := fs.Lookup("debug.timing").Value.(flag.Getter).Get().(bool)
+	goarch := fs.Lookup("arch").Value.(flag.Getter).Get().(string)
 
-	ps, lprog, err := Lint(c, fs.Args(), &Options{
-		Tags:      strings.Fields(tags),
-		LintTests: tests,
-		Ignores:   ignore,
-		GoVersion: version,
-	})
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	var colorize bool
+	switch color {
+	case "auto":
+		colorize = isTerminal(os.Stdout)
+	case "always":
+		colorize = true
+	case "never":
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported color mode %q\n", color)
+		os.Exit(2)
+	}
+
+	var f formatter
+	switch format {
+	case "text":
+		f = textFormatter{w: os.Stdout, urlBase: urlBase, function: showFunction, color: colorize, source: sourceLines{}}
+	case "json":
+		f = jsonFormatter{w: os.Stdout, urlBase: urlBase, snippets: snippets, context: int(snippetContext), source: sourceLines{}}
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", format)
+		os.Exit(2)
+	}
+
+	opt := &Options{
+		Tags:         strings.Fields(tags),
+		LintTests:    tests,
+		Ignores:      ignore,
+		GoVersion:    version,
+		MemoryBudget: budget << 20,
+		MaxTime:      maxTime,
+		GOARCH:       goarch,
+	}
+	if timing {
+		opt.Timings = os.Stderr
 	}
 	unclean := false
-	for _, p := range ps {
-		unclean = true
-		pos := lprog.Fset.Position(p.Position)
-		fmt.Printf("%v: %s\n", relativePositionString(pos), p.Text)
+	emit := func(ps []lint.Problem, lprog *loader.Program) {
+		for _, p := range ps {
+			unclean = true
+			f.Format(p, lprog.Fset.Position(p.Position))
+		}
+	}
+	if stream {
+		err := LintEach(c, fs.Args(), opt, emit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		ps, lprog, err := Lint(c, fs.Args(), opt)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		emit(ps, lprog)
 	}
 	if unclean {
 		os.Exit(1)
@@ -161,48 +239,171 @@ type Options struct {
 	LintTests bool
 	Ignores   string
 	GoVersion int
+
+	// MemoryBudget, if not zero, is the heap size in bytes above
+	// which dependencies are loaded without type-checking their
+	// function bodies.
+	MemoryBudget uint64
+
+	// GOARCH is the target architecture. It determines which files
+	// are loaded and the sizes of types. If empty, the architecture
+	// of build.Default is used.
+	GOARCH string
+
+	// MaxTime, if not zero, limits the time spent running checks on
+	// each set of packages that is linted at once. Checks that take
+	// longer are skipped with a warning. See lint.Linter.MaxTime.
+	MaxTime time.Duration
+
+	// Timings, if not nil, receives a report of the time spent
+	// loading, building SSA and running each check, for each set of
+	// packages that is linted at once.
+	Timings io.Writer
+
+	// Sources, if not nil, causes packages to be loaded from memory
+	// instead of the file system. It maps import paths to a mapping
+	// of file base names to file contents. Every imported package,
+	// including those of the standard library, has to be provided;
+	// small stubs declaring only the used identifiers suffice.
+	Sources map[string]map[string]string
 }
 
 func Lint(c lint.Checker, pkgs []string, opt *Options) ([]lint.Problem, *loader.Program, error) {
 	// TODO(dh): Instead of returning the loader.Program, we should
 	// store token.Position instead of token.Pos in lint.Problem.
+	runner, paths, goFiles, err := newRunner(c, pkgs, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	lprog, partial, err := runner.load(paths, goFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	return runner.lint(lprog, partial), lprog, nil
+}
+
+// LintEach is like Lint, but loads and lints one package at a time,
+// calling fn with the problems of each package as soon as they're
+// known. This lowers peak memory usage and produces output earlier,
+// at the cost of checks that look at the whole program only seeing
+// one package at a time.
+func LintEach(c lint.Checker, pkgs []string, opt *Options, fn func([]lint.Problem, *loader.Program)) error {
+	runner, paths, goFiles, err := newRunner(c, pkgs, opt)
+	if err != nil {
+		return err
+	}
+	if goFiles {
+		// Files given on the command line form a single package.
+		lprog, partial, err := runner.load(paths, true)
+		if err != nil {
+			return err
+		}
+		fn(runner.lint(lprog, partial), lprog)
+		return nil
+	}
+	for _, path := range paths {
+		lprog, partial, err := runner.load([]string{path}, false)
+		if err != nil {
+			return err
+		}
+		fn(runner.lint(lprog, partial), lprog)
+	}
+	return nil
+}
+
+func newRunner(c lint.Checker, pkgs []string, opt *Options) (*runner, []string, bool, error) {
 	if opt == nil {
 		opt = &Options{}
 	}
 	ignores, err := parseIgnore(opt.Ignores)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, false, err
 	}
 	runner := &runner{
-		checker: c,
-		tags:    opt.Tags,
-		ignores: ignores,
-		version: opt.GoVersion,
+		checker:      c,
+		tags:         opt.Tags,
+		ignores:      ignores,
+		version:      opt.GoVersion,
+		tests:        opt.LintTests,
+		memoryBudget: opt.MemoryBudget,
+		maxTime:      opt.MaxTime,
+		goarch:       opt.GOARCH,
+		timings:      opt.Timings,
 	}
-	paths := gotool.ImportPaths(pkgs)
-	goFiles, err := runner.resolveRelative(paths)
-	if err != nil {
-		return nil, nil, err
+	var paths []string
+	var goFiles bool
+	if opt.Sources != nil {
+		// In-memory sources are hermetic; there is no file system to
+		// expand patterns against or to resolve relative paths in.
+		runner.ctx = buildutil.FakeContext(opt.Sources)
+		paths = pkgs
+	} else {
+		runner.ctx = &build.Context{}
+		*runner.ctx = build.Default
+		paths = gotool.ImportPaths(pkgs)
+		goFiles, err = runner.resolveRelative(paths)
+		if err != nil {
+			return nil, nil, false, err
+		}
 	}
-	ctx := build.Default
-	ctx.BuildTags = runner.tags
+	runner.ctx.BuildTags = runner.tags
+	if runner.goarch != "" {
+		runner.ctx.GOARCH = runner.goarch
+	}
+	return runner, paths, goFiles, nil
+}
+
+// load loads the packages to lint. If the memory budget is exceeded
+// while loading, the function bodies of the remaining dependencies
+// aren't type-checked, and their import paths are returned in
+// partial.
+func (runner *runner) load(paths []string, goFiles bool) (lprog *loader.Program, partial map[string]bool, err error) {
 	conf := &loader.Config{
-		Build:      &ctx,
+		Build:      runner.ctx,
 		ParserMode: parser.ParseComments,
 		ImportPkgs: map[string]bool{},
 	}
+	conf.TypeChecker.Sizes = runner.sizes()
 	if goFiles {
 		conf.CreateFromFilenames("adhoc", paths...)
 	} else {
 		for _, path := range paths {
-			conf.ImportPkgs[path] = opt.LintTests
+			conf.ImportPkgs[path] = runner.tests
+		}
+	}
+	if runner.memoryBudget > 0 {
+		// The packages being linted always get checked in full.
+		initial := map[string]bool{"adhoc": goFiles}
+		for path := range conf.ImportPkgs {
+			initial[path] = true
+		}
+		partial = map[string]bool{}
+		var mu sync.Mutex
+		exceeded := false
+		conf.TypeCheckFuncBodies = func(path string) bool {
+			if initial[strings.TrimSuffix(path, "_test")] {
+				return true
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !exceeded {
+				var stats runtime.MemStats
+				runtime.ReadMemStats(&stats)
+				exceeded = stats.HeapAlloc > runner.memoryBudget
+			}
+			if exceeded {
+				partial[path] = true
+			}
+			return !exceeded
 		}
 	}
-	lprog, err := conf.Load()
+	t := time.Now()
+	lprog, err = conf.Load()
 	if err != nil {
 		return nil, nil, err
 	}
-	return runner.lint(lprog), lprog, nil
+	runner.loadTime = time.Since(t)
+	return lprog, partial, nil
 }
 
 func shortPath(path string) string {
@@ -237,11 +438,64 @@ func ProcessArgs(name string, c lint.Checker, args []string) {
 	ProcessFlagSet(c, flags)
 }
 
-func (runner *runner) lint(lprog *loader.Program) []lint.Problem {
+func (runner *runner) lint(lprog *loader.Program, partial map[string]bool) []lint.Problem {
 	l := &lint.Linter{
 		Checker:   runner.checker,
 		Ignores:   runner.ignores,
 		GoVersion: runner.version,
+		Partial:   partial,
+		MaxTime:   runner.maxTime,
+		Sizes:     runner.sizes(),
+		Build:     runner.ctx,
+	}
+	ps := l.Lint(lprog)
+
+	var paths []string
+	for _, pkg := range lprog.InitialPackages() {
+		paths = append(paths, pkg.Pkg.Path())
+	}
+	name := strings.Join(paths, " ")
+	if len(l.Skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s: skipped checks that took longer than %s: %s\n",
+			name, runner.maxTime, strings.Join(l.Skipped, ", "))
+	}
+	if runner.timings != nil {
+		l.Timings["load"] = runner.loadTime
+		printTimings(runner.timings, name, l.Timings)
+	}
+	return ps
+}
+
+func (runner *runner) sizes() types.Sizes {
+	return gcsizes.ForArch(runner.ctx.GOARCH)
+}
+
+// printTimings prints timings, longest first.
+func printTimings(w io.Writer, name string, timings map[string]time.Duration) {
+	var ts byDuration
+	for k, d := range timings {
+		ts = append(ts, timing{k, d})
+	}
+	sort.Sort(ts)
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	for _, t := range ts {
+		fmt.Fprintf(tw, "%s: %s:\t%s\n", name, t.name, t.d)
+	}
+	tw.Flush()
+}
+
+type timing struct {
+	name string
+	d    time.Duration
+}
+
+type byDuration []timing
+
+func (ts byDuration) Len() int      { return len(ts) }
+func (ts byDuration) Swap(i, j int) { ts[i], ts[j] = ts[j], ts[i] }
+func (ts byDuration) Less(i, j int) bool {
+	if ts[i].d != ts[j].d {
+		return ts[i].d > ts[j].d
 	}
-	return l.Lint(lprog)
+	return ts[i].name < ts[j].name
 }
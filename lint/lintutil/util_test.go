@@ -0,0 +1,347 @@
+package lintutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gm42/go-tools/lint"
+
+	"golang.org/x/tools/go/loader"
+)
+
+type funcChecker struct{}
+
+func (funcChecker) Init(*lint.Program) {}
+
+func (funcChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"TEST1000": func(j *lint.Job) {
+			for _, f := range j.Program.Files {
+				for _, decl := range f.Decls {
+					if fn, ok := decl.(*ast.FuncDecl); ok {
+						j.Errorf(fn, "function %s", fn.Name.Name)
+					}
+				}
+			}
+		},
+	}
+}
+
+func TestLintSources(t *testing.T) {
+	sources := map[string]map[string]string{
+		"errors": {
+			"errors.go": "package errors\n\nfunc New(text string) error { return nil }\n",
+		},
+		"example.com/pkg": {
+			"pkg.go": "package pkg\n\nimport \"errors\"\n\nfunc Fn() error { return errors.New(\"\") }\n",
+		},
+	}
+	ps, lprog, err := Lint(funcChecker{}, []string{"example.com/pkg"}, &Options{Sources: sources})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 {
+		t.Fatalf("got %d problems, want 1", len(ps))
+	}
+	if want := "function Fn (TEST1000)"; ps[0].Text != want {
+		t.Errorf("got problem %q, want %q", ps[0].Text, want)
+	}
+	if pos := lprog.Fset.Position(ps[0].Position); pos.Line != 5 {
+		t.Errorf("got problem on line %d, want 5", pos.Line)
+	}
+}
+
+func TestLintEach(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/a": {
+			"a.go": "package a\n\nfunc A() {}\n",
+		},
+		"example.com/b": {
+			"b.go": "package b\n\nimport \"example.com/a\"\n\nfunc B1() { a.A() }\n\nfunc B2() {}\n",
+		},
+	}
+	var got []string
+	err := LintEach(funcChecker{}, []string{"example.com/b", "example.com/a"}, &Options{Sources: sources}, func(ps []lint.Problem, lprog *loader.Program) {
+		for _, p := range ps {
+			got = append(got, p.Text)
+		}
+		got = append(got, "--")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"function B1 (TEST1000)", "function B2 (TEST1000)", "--", "function A (TEST1000)", "--"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLintMemoryBudget(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/a": {
+			"a.go": "package a\n\nfunc A() int { return 1 }\n",
+		},
+		"example.com/b": {
+			"b.go": "package b\n\nimport \"example.com/a\"\n\nfunc B() int { return a.A() }\n",
+		},
+	}
+	// A budget of one byte is always exceeded.
+	ps, _, err := Lint(funcChecker{}, []string{"example.com/b"}, &Options{Sources: sources, MemoryBudget: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].Text != "function B (TEST1000)" {
+		t.Errorf("got %v, want a single problem for B", ps)
+	}
+}
+
+func TestLintFunction(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/pkg": {
+			"pkg.go": "package pkg\n\ntype T struct{}\n\nfunc (*T) M() {}\n\nfunc Fn() {}\n",
+		},
+	}
+	ps, _, err := Lint(funcChecker{}, []string{"example.com/pkg"}, &Options{Sources: sources})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range ps {
+		got = append(got, p.Function)
+	}
+	want := []string{"example.com/pkg.T.M", "example.com/pkg.Fn"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	pos := token.Position{Filename: "/a.go", Line: 1, Column: 2}
+	textFormatter{w: &buf, function: true}.Format(ps[1], pos)
+	if want := "/a.go:1:2: example.com/pkg.Fn: function Fn (TEST1000)\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTextFormatterColor(t *testing.T) {
+	var buf bytes.Buffer
+	f := textFormatter{w: &buf, color: true, source: sourceLines{"/a.go": {"package pkg", "\tx := \"ü\" + y"}}}
+	p := lint.Problem{Text: "something is wrong (SA1000)", Check: "SA1000"}
+	f.Format(p, token.Position{Filename: "/a.go", Line: 2, Column: 12})
+	want := "\x1b[1m/a.go:2:12:\x1b[0m something is wrong \x1b[1;31m(SA1000)\x1b[0m\n" +
+		"\tx := \"ü\" + y\n" +
+		"\t         \x1b[1;31m^\x1b[0m\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	// Problems without source lines only get colorized.
+	buf.Reset()
+	p = lint.Problem{Text: "could be simpler (S1000)", Check: "S1000"}
+	f.Format(p, token.Position{Filename: "/b.go", Line: 1, Column: 1})
+	want = "\x1b[1m/b.go:1:1:\x1b[0m could be simpler \x1b[1;33m(S1000)\x1b[0m\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONFormatterSnippet(t *testing.T) {
+	var buf bytes.Buffer
+	f := jsonFormatter{w: &buf, snippets: true, context: 1, source: sourceLines{"/a.go": {"package pkg", "", "func Fn() {}"}}}
+	p := lint.Problem{Text: "function Fn (TEST1000)", Check: "TEST1000"}
+	f.Format(p, token.Position{Filename: "/a.go", Line: 3, Column: 1})
+	want := `"snippet":{"start_line":2,"text":"\nfunc Fn() {}\n"}`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %s, want it to contain %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	f.Format(p, token.Position{Filename: "/b.go", Line: 3, Column: 1})
+	if strings.Contains(buf.String(), "snippet") {
+		t.Errorf("got %s, want no snippet for a missing file", buf.String())
+	}
+}
+
+func TestLintGOARCH(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/pkg": {
+			"pkg_386.go":   "package pkg\n\nfunc Fn386() {}\n",
+			"pkg_amd64.go": "package pkg\n\nfunc FnAmd64() {}\n",
+		},
+	}
+	var got []string
+	c := sizesChecker{fn: func(sizes types.Sizes) {
+		got = append(got, fmt.Sprint(sizes.Sizeof(types.Typ[types.Int])))
+	}}
+	ps, _, err := Lint(c, []string{"example.com/pkg"}, &Options{Sources: sources, GOARCH: "386"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].Text != "function Fn386 (TEST1000)" {
+		t.Errorf("got %v, want a single problem for Fn386", ps)
+	}
+	if want := []string{"4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got int sizes %q, want %q", got, want)
+	}
+}
+
+func TestLintIgnoreExcludedFile(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/pkg": {
+			"pkg.go":     "package pkg\n",
+			"ignored.go": "// +build ignore\n\npackage pkg\n",
+		},
+	}
+	opt := &Options{Sources: sources, Ignores: "example.com/pkg/ignored.go:TEST1001"}
+	ps, _, err := Lint(excludedChecker{}, []string{"example.com/pkg"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 0 {
+		t.Errorf("got %v, want no problems", ps)
+	}
+}
+
+// excludedChecker reports a problem in ignored.go, which is
+// excluded from the build.
+type excludedChecker struct{}
+
+func (excludedChecker) Init(*lint.Program) {}
+
+func (excludedChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"TEST1001": func(j *lint.Job) {
+			fset := j.Program.Prog.Fset
+			dir := filepath.Dir(fset.File(j.Program.Files[0].Pos()).Name())
+			f, err := parser.ParseFile(fset, filepath.Join(dir, "ignored.go"), "package pkg\n", 0)
+			if err != nil {
+				panic(err)
+			}
+			j.Errorf(f, "excluded")
+		},
+	}
+}
+
+// sizesChecker passes the sizes of the program to fn.
+type sizesChecker struct {
+	fn func(types.Sizes)
+}
+
+func (sizesChecker) Init(*lint.Program) {}
+
+func (c sizesChecker) Funcs() map[string]lint.Func {
+	fns := funcChecker{}.Funcs()
+	fns["TEST1001"] = func(j *lint.Job) { c.fn(j.Program.Sizes) }
+	return fns
+}
+
+// slowChecker has a check that only finishes once done is closed.
+type slowChecker struct {
+	done chan struct{}
+}
+
+func (slowChecker) Init(*lint.Program) {}
+
+func (c slowChecker) Funcs() map[string]lint.Func {
+	fns := funcChecker{}.Funcs()
+	fns["TEST1001"] = func(j *lint.Job) {
+		<-c.done
+		j.Errorf(j.Program.Files[0], "slow")
+	}
+	return fns
+}
+
+func TestLintMaxTime(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/pkg": {
+			"pkg.go": "package pkg\n\nfunc Fn() {}\n",
+		},
+	}
+	c := slowChecker{done: make(chan struct{})}
+	defer close(c.done)
+	var timings bytes.Buffer
+	ps, _, err := Lint(c, []string{"example.com/pkg"}, &Options{Sources: sources, MaxTime: 100 * time.Millisecond, Timings: &timings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].Check != "TEST1000" {
+		t.Errorf("got %v, want a single problem of TEST1000", ps)
+	}
+	for _, name := range []string{"load", "ssa", "TEST1000"} {
+		if !strings.Contains(timings.String(), "example.com/pkg: "+name+":") {
+			t.Errorf("timings %q don't include %s", timings.String(), name)
+		}
+	}
+	if strings.Contains(timings.String(), "TEST1001") {
+		t.Errorf("timings %q include skipped check", timings.String())
+	}
+}
+
+func TestFormatURL(t *testing.T) {
+	p := lint.Problem{Text: "something is wrong (TEST1000)", Check: "TEST1000"}
+	pos := token.Position{Filename: "/a.go", Line: 1, Column: 2}
+
+	var buf bytes.Buffer
+	textFormatter{w: &buf, urlBase: "https://example.com/checks#"}.Format(p, pos)
+	if want := "/a.go:1:2: something is wrong (TEST1000, https://example.com/checks#TEST1000)\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	textFormatter{w: &buf}.Format(p, pos)
+	if want := "/a.go:1:2: something is wrong (TEST1000)\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func init() {
+	lint.Register(
+		&lint.Check{ID: "TEST2000"},
+		&lint.Check{ID: "TEST2001", MinGoVersion: 99},
+	)
+}
+
+// registryChecker has registered checks that don't need SSA, and
+// records the number of functions of the program in ssaFuncs.
+type registryChecker struct {
+	ssaFuncs *int
+}
+
+func (registryChecker) Init(*lint.Program) {}
+
+func (c registryChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"TEST2000": func(j *lint.Job) {
+			*c.ssaFuncs = len(j.Program.AllFunctions)
+			j.Errorf(j.Program.Files[0], "checked")
+		},
+		"TEST2001": func(j *lint.Job) { j.Errorf(j.Program.Files[0], "too new") },
+	}
+}
+
+func TestLintRegistry(t *testing.T) {
+	sources := map[string]map[string]string{
+		"example.com/pkg": {
+			"pkg.go": "package pkg\n\nfunc Fn() {}\n",
+		},
+	}
+	ssaFuncs := -1
+	ps, _, err := Lint(registryChecker{&ssaFuncs}, []string{"example.com/pkg"}, &Options{Sources: sources, GoVersion: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].Check != "TEST2000" {
+		t.Errorf("got %v, want a single problem of TEST2000", ps)
+	}
+	if ssaFuncs != 0 {
+		t.Errorf("got %d SSA functions, want none", ssaFuncs)
+	}
+}
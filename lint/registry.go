@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Severity describes how serious the problems reported by a check
+// are. It is informational only; all problems cause linters to exit
+// with a non-zero status.
+type Severity int
+
+const (
+	// SeverityError is the severity of checks that find bugs.
+	SeverityError Severity = iota
+	// SeverityWarning is the severity of checks that find style
+	// issues and code that could be simpler.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Check describes a check independently of the Checker that
+// implements it. Checkers register their checks with Register, which
+// lets linters describe checks without running them and decide what
+// work a set of checks requires.
+type Check struct {
+	ID string
+	Documentation
+	Severity Severity
+	// MinGoVersion is the minor version of the oldest Go release the
+	// check applies to, such as 8 for Go 1.8. The Linter doesn't run
+	// the check when targeting older releases.
+	MinGoVersion int
+	// SSA reports whether the check uses the SSA form of functions.
+	// The Linter only builds SSA if one of the checks it runs does.
+	SSA bool
+}
+
+var registry struct {
+	mu     sync.Mutex
+	checks map[string]*Check
+}
+
+// Register registers checks, usually from the init function of the
+// package that implements them. It panics if a check with the same ID
+// has already been registered.
+func Register(checks ...*Check) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.checks == nil {
+		registry.checks = map[string]*Check{}
+	}
+	for _, c := range checks {
+		if _, ok := registry.checks[c.ID]; ok {
+			panic(fmt.Sprintf("check %s registered twice", c.ID))
+		}
+		registry.checks[c.ID] = c
+	}
+}
+
+// LookupCheck returns the registered check with the given ID, or nil
+// if there is none.
+func LookupCheck(id string) *Check {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return registry.checks[id]
+}
@@ -9,6 +9,7 @@ package testutil // import "github.com/gm42/go-tools/lint/testutil"
 import (
 	"flag"
 	"fmt"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"io/ioutil"
@@ -66,8 +67,24 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 	conf := &loader.Config{
 		ParserMode: parser.ParseComments,
 	}
+	// Packages in testdata/src, such as stubs of third-party
+	// packages, can be imported by the test files.
+	if fi, err := os.Stat(filepath.Join(baseDir, "src")); err == nil && fi.IsDir() {
+		gopath, err := filepath.Abs(baseDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Setenv("GO111MODULE", os.Getenv("GO111MODULE"))
+		os.Setenv("GO111MODULE", "off")
+		ctx := build.Default
+		ctx.GOPATH = gopath
+		conf.Build = &ctx
+	}
 	sources := map[string][]byte{}
 	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
 		filename := path.Join(baseDir, fi.Name())
 		src, err := ioutil.ReadFile(filename)
 		if err != nil {
@@ -0,0 +1,99 @@
+package rewrite
+
+import (
+	"flag"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gm42/go-tools/internal/edit"
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+type rulesFlag []string
+
+func (f *rulesFlag) String() string { return strings.Join(*f, ",") }
+func (f *rulesFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// Main implements a command line tool that applies rewrites to
+// packages. The rewrites consist of fns and the rules in the files
+// named by -rules flags.
+func Main(fns ...Func) {
+	var (
+		fRules rulesFlag
+		fWrite bool
+		fDiff  bool
+		fTests bool
+		fTags  buildutil.TagsFlag
+	)
+	flag.Var(&fRules, "rules", "Apply the rules in `file`; may be repeated")
+	flag.BoolVar(&fWrite, "w", false, "Write the results to the files instead of listing the changed files")
+	flag.BoolVar(&fDiff, "d", false, "Print diffs instead of listing the changed files")
+	flag.BoolVar(&fTests, "tests", true, "Include tests")
+	flag.Var(&fTags, "tags", "List of build tags")
+	log.SetFlags(0)
+	flag.Parse()
+
+	for _, name := range fRules {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rules, err := ParseRules(name, src)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fns = append(fns, rules...)
+	}
+	if len(fns) == 0 {
+		log.Fatal("no rewrites; use -rules to specify some")
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = fTags
+	conf := loader.Config{
+		Build:      &ctx,
+		ParserMode: parser.ParseComments,
+	}
+	for _, path := range gotool.ImportPaths(flag.Args()) {
+		if fTests {
+			conf.ImportWithTests(path)
+		} else {
+			conf.Import(path)
+		}
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	files, err := Apply(lprog, fns)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, f := range files {
+		switch {
+		case fWrite:
+			fi, err := os.Stat(f.Name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := ioutil.WriteFile(f.Name, f.New, fi.Mode().Perm()); err != nil {
+				log.Fatal(err)
+			}
+		case fDiff:
+			os.Stdout.Write(edit.Diff(f.Name+".orig", f.Name, f.Old, f.New))
+		default:
+			fmt.Println(f.Name)
+		}
+	}
+}
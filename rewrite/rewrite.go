@@ -0,0 +1,193 @@
+// Package rewrite applies type-aware rewrites to Go code.
+//
+// Rewrites are either Go functions of type Func, or rules of the
+// form "pattern -> replacement", as accepted by ParseRules. Programs
+// that ship their own rewrites can pass them to Main.
+package rewrite // import "github.com/gm42/go-tools/rewrite"
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/loader"
+)
+
+// A Func rewrites a single node. It returns the node's replacement,
+// or nil to leave the node unchanged.
+//
+// Nodes are visited bottom-up, so the children of node may already
+// have been rewritten. Nodes created by rewrites have no type
+// information.
+type Func func(pass *Pass, node ast.Node) ast.Node
+
+// Pass provides a Func with information about the file being
+// rewritten.
+type Pass struct {
+	Fset *token.FileSet
+	Pkg  *types.Package
+	Info *types.Info
+	File *ast.File
+
+	prog *loader.Program
+}
+
+// ObjectOf returns the object that an identifier or a qualified
+// identifier refers to, or nil.
+func (pass *Pass) ObjectOf(expr ast.Expr) types.Object {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return pass.Info.ObjectOf(expr)
+	case *ast.SelectorExpr:
+		return pass.Info.ObjectOf(expr.Sel)
+	}
+	return nil
+}
+
+// Import returns the name by which the file refers to the package
+// with the given import path, adding an import declaration if
+// necessary. It doesn't check for conflicts with other names.
+func (pass *Pass) Import(importPath string) string {
+	for _, spec := range pass.File.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p != importPath {
+			continue
+		}
+		if spec.Name == nil {
+			return pass.packageName(importPath)
+		}
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name
+		}
+	}
+	astutil.AddImport(pass.Fset, pass.File, importPath)
+	return pass.packageName(importPath)
+}
+
+func (pass *Pass) packageName(importPath string) string {
+	if pkg := pass.prog.Package(importPath); pkg != nil {
+		return pkg.Pkg.Name()
+	}
+	return path.Base(importPath)
+}
+
+// File is a file changed by a rewrite.
+type File struct {
+	Name string
+	Old  []byte
+	New  []byte
+}
+
+// Apply applies rewrites to the files of the initial packages of
+// prog and returns the files that changed. Imports that are no
+// longer used after rewriting are removed, and the files are
+// formatted like gofmt does.
+//
+// Apply modifies the ASTs of prog.
+func Apply(prog *loader.Program, fns []Func) ([]File, error) {
+	var out []File
+	seen := map[*ast.File]bool{}
+	for _, pkg := range prog.InitialPackages() {
+		for _, f := range pkg.Files {
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			pass := &Pass{
+				Fset: prog.Fset,
+				Pkg:  pkg.Pkg,
+				Info: &pkg.Info,
+				File: f,
+				prog: prog,
+			}
+			changed := false
+			astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+				for _, fn := range fns {
+					if repl := fn(pass, c.Node()); repl != nil {
+						c.Replace(repl)
+						changed = true
+						break
+					}
+				}
+				return true
+			})
+			if !changed {
+				continue
+			}
+			removeUnusedImports(prog.Fset, &pkg.Info, f)
+
+			name := prog.Fset.File(f.Pos()).Name()
+			old, err := ioutil.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			var buf bytes.Buffer
+			if err := format.Node(&buf, prog.Fset, f); err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(old, buf.Bytes()) {
+				out = append(out, File{Name: name, Old: old, New: buf.Bytes()})
+			}
+		}
+	}
+	return out, nil
+}
+
+// removeUnusedImports removes the imports of f that no qualified
+// identifier refers to anymore.
+func removeUnusedImports(fset *token.FileSet, info *types.Info, f *ast.File) {
+	used := map[string]bool{}
+	ast.Inspect(f, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	var unused []*ast.ImportSpec
+	for _, spec := range f.Imports {
+		if spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == ".") {
+			continue
+		}
+		// Imports added by rewrites have no type information; they
+		// are used by definition.
+		pkgName, ok := info.Implicits[spec].(*types.PkgName)
+		if spec.Name != nil {
+			pkgName, ok = info.Defs[spec.Name].(*types.PkgName)
+		}
+		if ok && !used[pkgName.Name()] {
+			unused = append(unused, spec)
+		}
+	}
+	if len(unused) == 0 {
+		return
+	}
+	specs := map[*ast.GenDecl]int{}
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			specs[gen] = len(gen.Specs)
+		}
+	}
+	for _, spec := range unused {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		var name string
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		astutil.DeleteNamedImport(fset, f, name, p)
+	}
+	// Drop the parentheses of declarations that are down to a single
+	// import.
+	for gen, n := range specs {
+		if len(gen.Specs) == 1 && n > 1 {
+			gen.Lparen = token.NoPos
+			gen.Rparen = token.NoPos
+		}
+	}
+}
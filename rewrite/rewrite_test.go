@@ -0,0 +1,133 @@
+package rewrite
+
+import (
+	"go/build"
+	"go/parser"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// load writes files to a temporary GOPATH and loads the package at
+// path from it. The caller has to remove the returned directory.
+func load(t *testing.T, files map[string]string, path string) (*loader.Program, string) {
+	dir, err := ioutil.TempDir("", "rewrite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		name = filepath.Join(dir, "src", name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := build.Default
+	ctx.GOPATH = dir
+	ctx.CgoEnabled = false
+	conf := loader.Config{
+		Build:      &ctx,
+		ParserMode: parser.ParseComments,
+	}
+	conf.Import(path)
+	lprog, err := conf.Load()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return lprog, dir
+}
+
+func TestRules(t *testing.T) {
+	defer os.Setenv("GO111MODULE", os.Getenv("GO111MODULE"))
+	os.Setenv("GO111MODULE", "off")
+
+	files := map[string]string{
+		"example.com/old/old.go": "package old\n\nfunc Read(s string) string { return s }\n",
+		"example.com/new/new.go": "package new\n\nfunc Read(s string) string { return s }\n",
+		"example.com/p/p.go": `package p
+
+import o "example.com/old"
+
+// Read reads.
+func Read(s string) string {
+	return o.Read(s + s)
+}
+
+func Same(s string) bool {
+	return s == s
+}
+
+type T struct{}
+
+func (T) Read(s string) string { return s }
+
+func Method(s string) string {
+	return T{}.Read(s)
+}
+`,
+	}
+	rules := `
+# Move Read.
+import "example.com/old"
+import "example.com/new"
+old.Read(x) -> new.Read(x)
+x == x -> true
+`
+	fns, err := ParseRules("rules", []byte(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lprog, dir := load(t, files, "example.com/p")
+	defer os.RemoveAll(dir)
+	changed, err := Apply(lprog, fns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 {
+		t.Fatalf("got %d changed files, want 1", len(changed))
+	}
+	want := `package p
+
+import "example.com/new"
+
+// Read reads.
+func Read(s string) string {
+	return new.Read(s + s)
+}
+
+func Same(s string) bool {
+	return true
+}
+
+type T struct{}
+
+func (T) Read(s string) string { return s }
+
+func Method(s string) string {
+	return T{}.Read(s)
+}
+`
+	if got := string(changed[0].New); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []string{
+		"x",
+		"x -> y -> z",
+		"x( -> y",
+		"import",
+	}
+	for _, src := range tests {
+		if _, err := ParseRules("rules", []byte(src)); err == nil {
+			t.Errorf("ParseRules(%q) succeeded, want error", src)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package rewrite
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseRules parses rewrite rules. Each line of src holds either an
+// import declaration or a rule of the form "pattern -> replacement",
+// where pattern and replacement are Go expressions. Empty lines and
+// lines starting with # are ignored. name is used in error messages.
+//
+// Like in gofmt -r, single-letter lowercase identifiers are
+// wildcards that match any expression; all occurrences of a wildcard
+// in a pattern have to match the same expression. Identifiers
+// qualified by the name of a package imported by an import
+// declaration, as in
+//
+//	import "io/ioutil"
+//	ioutil.ReadAll(r) -> io.ReadAll(r)
+//
+// match the members of that package no matter what name the code
+// being rewritten imports it under. In replacements, they make sure
+// that the package is imported.
+func ParseRules(name string, src []byte) ([]Func, error) {
+	imports := map[string]string{}
+	var fns []Func
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasPrefix(text, "import ") {
+			f, err := parser.ParseFile(token.NewFileSet(), "", "package p; "+text, 0)
+			if err != nil || len(f.Imports) != 1 {
+				return nil, fmt.Errorf("%s:%d: malformed import declaration", name, line)
+			}
+			spec := f.Imports[0]
+			p, _ := strconv.Unquote(spec.Path.Value)
+			pkgName := path.Base(p)
+			if spec.Name != nil {
+				pkgName = spec.Name.Name
+			}
+			imports[pkgName] = p
+			continue
+		}
+		parts := strings.Split(text, "->")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: rule must be of the form 'pattern -> replacement'", name, line)
+		}
+		pattern, err := parser.ParseExpr(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern: %s", name, line, err)
+		}
+		replacement, err := parser.ParseExpr(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid replacement: %s", name, line, err)
+		}
+		r := &rule{
+			pattern:     pattern,
+			replacement: replacement,
+			imports:     map[string]string{},
+		}
+		for k, v := range imports {
+			r.imports[k] = v
+		}
+		fns = append(fns, r.rewrite)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fns, nil
+}
+
+type rule struct {
+	pattern     ast.Expr
+	replacement ast.Expr
+	// imports maps package names used in the rule to import paths.
+	imports map[string]string
+}
+
+func (r *rule) rewrite(pass *Pass, node ast.Node) ast.Node {
+	expr, ok := node.(ast.Expr)
+	if !ok {
+		return nil
+	}
+	m := &matcher{pass: pass, rule: r, bindings: map[string]reflect.Value{}}
+	if !m.match(reflect.ValueOf(r.pattern), reflect.ValueOf(expr), true) {
+		return nil
+	}
+	repl := m.subst(reflect.ValueOf(r.replacement), reflect.ValueOf(expr.Pos()))
+	if m.failed {
+		return nil
+	}
+	return repl.Interface().(ast.Expr)
+}
+
+var (
+	identType         = reflect.TypeOf((*ast.Ident)(nil))
+	selectorType      = reflect.TypeOf((*ast.SelectorExpr)(nil))
+	objectPtrType     = reflect.TypeOf((*ast.Object)(nil))
+	scopePtrType      = reflect.TypeOf((*ast.Scope)(nil))
+	commentGroupType  = reflect.TypeOf((*ast.CommentGroup)(nil))
+	positionType      = reflect.TypeOf(token.NoPos)
+	exprInterfaceType = reflect.TypeOf((*ast.Expr)(nil)).Elem()
+)
+
+func isWildcard(name string) bool {
+	r, size := utf8.DecodeRuneInString(name)
+	return size == len(name) && unicode.IsLower(r)
+}
+
+type matcher struct {
+	pass     *Pass
+	rule     *rule
+	bindings map[string]reflect.Value
+	// failed is set if a substitution produced an invalid AST, such
+	// as an expression where only an identifier is allowed.
+	failed bool
+}
+
+// importedMember returns the import path and name of the package
+// member that expr, a part of the rule, refers to.
+func (m *matcher) importedMember(expr reflect.Value) (string, string, bool) {
+	if expr.Type() != selectorType {
+		return "", "", false
+	}
+	sel := expr.Interface().(*ast.SelectorExpr)
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	p, ok := m.rule.imports[ident.Name]
+	return p, sel.Sel.Name, ok
+}
+
+// match reports whether val matches pattern. If wildcards is false,
+// identifiers in pattern only match identical identifiers.
+func (m *matcher) match(pattern, val reflect.Value, wildcards bool) bool {
+	if wildcards && pattern.IsValid() && pattern.Type() == identType {
+		name := pattern.Interface().(*ast.Ident).Name
+		if isWildcard(name) && val.IsValid() && val.Type().Implements(exprInterfaceType) {
+			if old, ok := m.bindings[name]; ok {
+				return m.match(old, val, false)
+			}
+			m.bindings[name] = val
+			return true
+		}
+	}
+	if wildcards && pattern.IsValid() && val.IsValid() {
+		if p, name, ok := m.importedMember(pattern); ok {
+			expr, ok := val.Interface().(ast.Expr)
+			if !ok {
+				return false
+			}
+			obj := m.pass.ObjectOf(expr)
+			return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == p && obj.Name() == name
+		}
+	}
+
+	if !pattern.IsValid() || !val.IsValid() {
+		return !pattern.IsValid() && !val.IsValid()
+	}
+	if pattern.Type() != val.Type() {
+		return false
+	}
+	switch pattern.Type() {
+	case positionType, objectPtrType, scopePtrType, commentGroupType:
+		return true
+	}
+
+	p := reflect.Indirect(pattern)
+	v := reflect.Indirect(val)
+	if !p.IsValid() || !v.IsValid() {
+		return !p.IsValid() && !v.IsValid()
+	}
+	switch p.Kind() {
+	case reflect.Slice:
+		if p.Len() != v.Len() {
+			return false
+		}
+		for i := 0; i < p.Len(); i++ {
+			if !m.match(p.Index(i), v.Index(i), wildcards) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < p.NumField(); i++ {
+			if !m.match(p.Field(i), v.Field(i), wildcards) {
+				return false
+			}
+		}
+		return true
+	case reflect.Interface:
+		return m.match(p.Elem(), v.Elem(), wildcards)
+	}
+	return p.Interface() == v.Interface()
+}
+
+// subst returns a copy of pattern with wildcards replaced by their
+// bindings and positions set to pos.
+func (m *matcher) subst(pattern, pos reflect.Value) reflect.Value {
+	if !pattern.IsValid() {
+		return reflect.Value{}
+	}
+	if pattern.Type() == identType {
+		name := pattern.Interface().(*ast.Ident).Name
+		if old, ok := m.bindings[name]; ok && isWildcard(name) {
+			return old
+		}
+	}
+	if p, name, ok := m.importedMember(pattern); ok {
+		return reflect.ValueOf(&ast.SelectorExpr{
+			X:   &ast.Ident{NamePos: pos.Interface().(token.Pos), Name: m.pass.Import(p)},
+			Sel: &ast.Ident{NamePos: pos.Interface().(token.Pos), Name: name},
+		})
+	}
+	switch pattern.Type() {
+	case positionType:
+		if !pattern.Interface().(token.Pos).IsValid() {
+			return pattern
+		}
+		return pos
+	case objectPtrType, scopePtrType, commentGroupType:
+		return reflect.Zero(pattern.Type())
+	}
+
+	switch pattern.Kind() {
+	case reflect.Slice:
+		v := reflect.MakeSlice(pattern.Type(), pattern.Len(), pattern.Len())
+		for i := 0; i < pattern.Len(); i++ {
+			m.set(v.Index(i), m.subst(pattern.Index(i), pos))
+		}
+		return v
+	case reflect.Struct:
+		v := reflect.New(pattern.Type()).Elem()
+		for i := 0; i < pattern.NumField(); i++ {
+			m.set(v.Field(i), m.subst(pattern.Field(i), pos))
+		}
+		return v
+	case reflect.Ptr:
+		v := reflect.New(pattern.Type()).Elem()
+		if elem := pattern.Elem(); elem.IsValid() {
+			v.Set(m.subst(elem, pos).Addr())
+		}
+		return v
+	case reflect.Interface:
+		v := reflect.New(pattern.Type()).Elem()
+		if elem := pattern.Elem(); elem.IsValid() {
+			m.set(v, m.subst(elem, pos))
+		}
+		return v
+	}
+	return pattern
+}
+
+func (m *matcher) set(dst, src reflect.Value) {
+	if !src.IsValid() {
+		return
+	}
+	if !src.Type().AssignableTo(dst.Type()) {
+		m.failed = true
+		return
+	}
+	dst.Set(src)
+}
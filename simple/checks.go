@@ -0,0 +1,51 @@
+package simple
+
+import "github.com/gm42/go-tools/lint"
+
+// checks describes the checks of the Checker for the check registry.
+// Their titles and texts come from the generated documentation, and
+// all of them are warnings.
+var checks = []*lint.Check{
+	{ID: "S1000"},
+	{ID: "S1001"},
+	{ID: "S1002"},
+	{ID: "S1003"},
+	{ID: "S1004"},
+	{ID: "S1005"},
+	{ID: "S1006"},
+	{ID: "S1007"},
+	{ID: "S1008"},
+	{ID: "S1009"},
+	{ID: "S1010"},
+	{ID: "S1011"},
+	{ID: "S1012"},
+	{ID: "S1013"},
+	{ID: "S1016"},
+	{ID: "S1017"},
+	{ID: "S1018"},
+	{ID: "S1019"},
+	{ID: "S1020"},
+	{ID: "S1021"},
+	{ID: "S1023"},
+	{ID: "S1024", MinGoVersion: 8},
+	{ID: "S1025"},
+	{ID: "S1026"},
+	{ID: "S1028"},
+	{ID: "S1029", SSA: true},
+	{ID: "S1030"},
+	{ID: "S1031"},
+	{ID: "S1032"},
+	{ID: "S1033"},
+	{ID: "S1034", MinGoVersion: 13},
+	{ID: "S1035"},
+	{ID: "S1036"},
+	{ID: "S1037"},
+}
+
+func init() {
+	for _, c := range checks {
+		c.Documentation = *docs[c.ID]
+		c.Severity = lint.SeverityWarning
+	}
+	lint.Register(checks...)
+}
@@ -2,11 +2,13 @@
 package simple // import "github.com/gm42/go-tools/simple"
 
 import (
+	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/token"
 	"go/types"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/gm42/go-tools/internal/sharedcheck"
@@ -16,9 +18,16 @@ import (
 	"golang.org/x/tools/go/types/typeutil"
 )
 
+//go:generate go run ../internal/gendocs/gendocs.go -pkg simple -o docs.go ../cmd/gosimple/docs/checks
+
 type Checker struct {
 	CheckGenerated bool
-	MS             *typeutil.MethodSetCache
+	// HTTPDefaultClient enables S1036, which suggests using
+	// http.DefaultClient instead of zero http.Client values. It is
+	// off by default because sharing the default client also shares
+	// its connections with the rest of the program.
+	HTTPDefaultClient bool
+	MS                *typeutil.MethodSetCache
 
 	nodeFns map[ast.Node]*ssa.Function
 }
@@ -34,7 +43,7 @@ func (c *Checker) Init(prog *lint.Program) {
 }
 
 func (c *Checker) Funcs() map[string]lint.Func {
-	return map[string]lint.Func{
+	fns := map[string]lint.Func{
 		"S1000": c.LintSingleCaseSelect,
 		"S1001": c.LintLoopCopy,
 		"S1002": c.LintIfBoolCmp,
@@ -67,7 +76,17 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"S1029": c.LintRangeStringRunes,
 		"S1030": c.LintBytesBufferConversions,
 		"S1031": c.LintNilCheckAroundRange,
+		"S1032": c.LintSprintfConversion,
+		"S1033": c.LintUnnecessaryBuffer,
+		"S1034": c.LintErrorsWrap,
+		"S1035": c.LintContextInScope,
+		"S1036": nil,
+		"S1037": c.LintFieldByFieldStruct,
+	}
+	if c.HTTPDefaultClient {
+		fns["S1036"] = c.LintHTTPDefaultClient
 	}
+	return fns
 }
 
 func (c *Checker) filterGenerated(files []*ast.File) []*ast.File {
@@ -537,6 +556,19 @@ func (c *Checker) LintIfReturn(j *lint.Job) {
 //   if x != nil && len(x) > N {}
 //   if x != nil && len(x) >= N {} (where N != 0)
 //
+// isSelectorChain reports whether expr is an identifier, or a chain
+// of field selections like a.b.c, which evaluates to the same value
+// every time.
+func isSelectorChain(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isSelectorChain(expr.X)
+	}
+	return false
+}
+
 func (c *Checker) LintRedundantNilCheckWithLen(j *lint.Job) {
 	isConstZero := func(expr ast.Expr) (isConst bool, isZero bool) {
 		_, ok := expr.(*ast.BasicLit)
@@ -576,8 +608,8 @@ func (c *Checker) LintRedundantNilCheckWithLen(j *lint.Job) {
 		if !eqNil && x.Op != token.NEQ {
 			return true
 		}
-		xx, ok := x.X.(*ast.Ident)
-		if !ok {
+		xx := x.X
+		if !isSelectorChain(xx) {
 			return true
 		}
 		if !j.IsNil(x.Y) {
@@ -600,11 +632,7 @@ func (c *Checker) LintRedundantNilCheckWithLen(j *lint.Job) {
 		if !ok || yxFun.Name != "len" || len(yx.Args) != 1 {
 			return true
 		}
-		yxArg, ok := yx.Args[0].(*ast.Ident)
-		if !ok {
-			return true
-		}
-		if yxArg.Name != xx.Name {
+		if j.Render(yx.Args[0]) != j.Render(xx) {
 			return true
 		}
 
@@ -643,7 +671,7 @@ func (c *Checker) LintRedundantNilCheckWithLen(j *lint.Job) {
 		// finally check that xx type is one of array, slice, map or chan
 		// this is to prevent false positive in case if xx is a pointer to an array
 		var nilType string
-		switch j.Program.Info.TypeOf(xx).(type) {
+		switch j.Program.Info.TypeOf(xx).Underlying().(type) {
 		case *types.Slice:
 			nilType = "nil slices"
 		case *types.Map:
@@ -813,9 +841,6 @@ func (c *Checker) LintTimeSince(j *lint.Job) {
 }
 
 func (c *Checker) LintTimeUntil(j *lint.Job) {
-	if !j.IsGoVersion(8) {
-		return
-	}
 	fn := func(node ast.Node) bool {
 		call, ok := node.(*ast.CallExpr)
 		if !ok {
@@ -1819,3 +1844,645 @@ func (c *Checker) LintNilCheckAroundRange(j *lint.Job) {
 		ast.Inspect(f, fn)
 	}
 }
+
+func (c *Checker) LintSprintfConversion(j *lint.Job) {
+	// conversion returns the strconv call that formats x of type
+	// typ like the verb does, or the empty string.
+	conversion := func(verb string, x string, typ types.Type) string {
+		switch verb {
+		case "%d", "%v":
+			switch {
+			case types.Identical(typ, types.Typ[types.Int]):
+				return fmt.Sprintf("strconv.Itoa(%s)", x)
+			case types.Identical(typ, types.Typ[types.Int64]):
+				return fmt.Sprintf("strconv.FormatInt(%s, 10)", x)
+			case types.Identical(typ, types.Typ[types.Uint64]):
+				return fmt.Sprintf("strconv.FormatUint(%s, 10)", x)
+			}
+		}
+		switch verb {
+		case "%t", "%v":
+			if types.Identical(typ, types.Typ[types.Bool]) {
+				return fmt.Sprintf("strconv.FormatBool(%s)", x)
+			}
+		}
+		return ""
+	}
+	// errors.New(fmt.Sprintf(...)) is flagged by S1028.
+	skip := map[ast.Expr]bool{}
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || skip[call] {
+			return true
+		}
+		var verb string
+		var arg ast.Expr
+		switch {
+		case j.IsCallToAST(call, "errors.New"):
+			if len(call.Args) == 1 {
+				skip[call.Args[0]] = true
+			}
+			return true
+		case j.IsCallToAST(call, "fmt.Sprintf"):
+			if len(call.Args) != 2 {
+				return true
+			}
+			s, ok := j.ExprToString(call.Args[0])
+			if !ok {
+				return true
+			}
+			verb, arg = s, call.Args[1]
+		case j.IsCallToAST(call, "fmt.Sprint"):
+			if len(call.Args) != 1 || call.Ellipsis.IsValid() {
+				return true
+			}
+			verb, arg = "%v", call.Args[0]
+		default:
+			return true
+		}
+		typ := j.Program.Info.TypeOf(arg)
+		if typ == nil {
+			return true
+		}
+		if len(call.Args) == 2 && verb == "%v" && types.Identical(typ, types.Typ[types.String]) {
+			// fmt.Sprint(s) is flagged by S1026 and fmt.Sprintf("%s", s)
+			// by S1025.
+			j.Errorf(call, "the argument is already a string, there's no need to use fmt.Sprintf")
+			return true
+		}
+		if repl := conversion(verb, j.Render(arg), typ); repl != "" {
+			j.Errorf(call, "should use %s instead of %s", repl, j.Render(call))
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) LintUnnecessaryBuffer(j *lint.Job) {
+	isBuffer := func(T types.Type) bool {
+		named, ok := T.(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			return false
+		}
+		switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+		case "bytes.Buffer", "strings.Builder":
+			return true
+		}
+		return false
+	}
+	// method returns the name of the method called on obj and the
+	// call's arguments, if expr is such a call.
+	method := func(expr ast.Expr, obj types.Object) (string, []ast.Expr, bool) {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return "", nil, false
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return "", nil, false
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || j.Program.Info.ObjectOf(ident) != obj {
+			return "", nil, false
+		}
+		return sel.Sel.Name, call.Args, true
+	}
+	uses := func(node ast.Node, obj types.Object) int {
+		n := 0
+		ast.Inspect(node, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && j.Program.Info.Uses[ident] == obj {
+				n++
+			}
+			return true
+		})
+		return n
+	}
+	// checkBlock looks for buffers that are declared in a block,
+	// written to with WriteString and then turned into a string,
+	// without being used in any other way.
+	checkBlock := func(block *ast.BlockStmt) {
+		for i, stmt := range block.List {
+			var obj types.Object
+			switch stmt := stmt.(type) {
+			case *ast.DeclStmt:
+				gen, ok := stmt.Decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+					continue
+				}
+				spec := gen.Specs[0].(*ast.ValueSpec)
+				if len(spec.Names) != 1 || len(spec.Values) != 0 {
+					continue
+				}
+				obj = j.Program.Info.ObjectOf(spec.Names[0])
+			case *ast.AssignStmt:
+				if stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+					continue
+				}
+				lit, ok := stmt.Rhs[0].(*ast.CompositeLit)
+				if !ok || len(lit.Elts) != 0 {
+					continue
+				}
+				ident, ok := stmt.Lhs[0].(*ast.Ident)
+				if !ok {
+					continue
+				}
+				obj = j.Program.Info.ObjectOf(ident)
+			}
+			if obj == nil || !isBuffer(obj.Type()) {
+				continue
+			}
+
+			var writes []ast.Expr
+			var str ast.Node
+			ok := true
+			for _, stmt := range block.List[i+1:] {
+				n := uses(stmt, obj)
+				if n == 0 {
+					continue
+				}
+				if str != nil || n != 1 {
+					ok = false
+					break
+				}
+				if expr, isExpr := stmt.(*ast.ExprStmt); isExpr {
+					if name, args, isCall := method(expr.X, obj); isCall && name == "WriteString" && len(args) == 1 {
+						writes = append(writes, args[0])
+						continue
+					}
+				}
+				// The only other use has to be a call to String.
+				ast.Inspect(stmt, func(node ast.Node) bool {
+					if expr, isExpr := node.(ast.Expr); isExpr {
+						if name, args, isCall := method(expr, obj); isCall && name == "String" && len(args) == 0 {
+							str = node
+							return false
+						}
+					}
+					return true
+				})
+				if str == nil {
+					ok = false
+					break
+				}
+			}
+			if !ok || str == nil || len(writes) == 0 {
+				continue
+			}
+
+			if len(writes) == 1 {
+				j.Errorf(str, "should use %s directly instead of writing it to a %s first", j.Render(writes[0]), types.TypeString(obj.Type(), nil))
+				continue
+			}
+			var parts []string
+			for _, w := range writes {
+				if j.Program.Info.Types[w].Value == nil {
+					ok = false
+					break
+				}
+				parts = append(parts, j.Render(w))
+			}
+			if ok {
+				j.Errorf(str, "should use %s instead of a %s", strings.Join(parts, " + "), types.TypeString(obj.Type(), nil))
+			}
+		}
+	}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			checkBlock(node)
+		case *ast.CallExpr:
+			if !j.IsCallToAST(node, "io.WriteString") || len(node.Args) != 2 {
+				return true
+			}
+			T := j.Program.Info.TypeOf(node.Args[0])
+			if T == nil || types.IsInterface(T) {
+				return true
+			}
+			obj, _, _ := types.LookupFieldOrMethod(T, true, nil, "WriteString")
+			fn, ok := obj.(*types.Func)
+			if !ok {
+				return true
+			}
+			sig := fn.Type().(*types.Signature)
+			if sig.Params().Len() != 1 || !types.Identical(sig.Params().At(0).Type(), types.Typ[types.String]) {
+				return true
+			}
+			w := node.Args[0]
+			if unary, ok := w.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+				// Methods on addressable values don't need the explicit
+				// address operator.
+				w = unary.X
+			}
+			j.Errorf(node, "should use %s.WriteString(%s) instead of %s", j.Render(w), j.Render(node.Args[1]), j.Render(node))
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) LintErrorsWrap(j *lint.Job) {
+	// nonNil returns the variables that cond, the condition of an if
+	// statement, proves not to be nil.
+	var nonNil func(cond ast.Expr) []types.Object
+	nonNil = func(cond ast.Expr) []types.Object {
+		bin, ok := cond.(*ast.BinaryExpr)
+		if !ok {
+			return nil
+		}
+		switch bin.Op {
+		case token.LAND:
+			return append(nonNil(bin.X), nonNil(bin.Y)...)
+		case token.NEQ:
+			x, y := bin.X, bin.Y
+			if j.IsNil(x) {
+				x, y = y, x
+			}
+			ident, ok := x.(*ast.Ident)
+			if !ok || !j.IsNil(y) {
+				return nil
+			}
+			if obj := j.Program.Info.ObjectOf(ident); obj != nil {
+				return []types.Object{obj}
+			}
+		}
+		return nil
+	}
+	// written returns the variables that are assigned to, or whose
+	// address is taken, in node.
+	written := func(node ast.Node) map[types.Object]bool {
+		objs := map[types.Object]bool{}
+		mark := func(expr ast.Expr) {
+			if ident, ok := expr.(*ast.Ident); ok {
+				if obj := j.Program.Info.ObjectOf(ident); obj != nil {
+					objs[obj] = true
+				}
+			}
+		}
+		ast.Inspect(node, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range node.Lhs {
+					mark(lhs)
+				}
+			case *ast.RangeStmt:
+				if node.Tok == token.ASSIGN {
+					mark(node.Key)
+					mark(node.Value)
+				}
+			case *ast.UnaryExpr:
+				if node.Op == token.AND {
+					mark(node.X)
+				}
+			}
+			return true
+		})
+		return objs
+	}
+	// errors.Wrap returns nil for nil errors, but fmt.Errorf never
+	// does, so only calls wrapping errors known not to be nil are
+	// flagged.
+	seen := map[*ast.CallExpr]bool{}
+	check := func(call *ast.CallExpr, objs []types.Object) {
+		if seen[call] || len(call.Args) < 2 {
+			return
+		}
+		ident, ok := call.Args[0].(*ast.Ident)
+		if !ok {
+			return
+		}
+		known := false
+		for _, obj := range objs {
+			if j.Program.Info.ObjectOf(ident) == obj {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return
+		}
+		var format string
+		var args []ast.Expr
+		switch {
+		case j.IsCallToAST(call, "github.com/pkg/errors.Wrap"):
+			if len(call.Args) != 2 {
+				return
+			}
+			s, ok := j.ExprToString(call.Args[1])
+			if !ok {
+				j.Errorf(call, "should use fmt.Errorf with %%w instead of errors.Wrap")
+				seen[call] = true
+				return
+			}
+			format = strings.Replace(s, "%", "%%", -1)
+		case j.IsCallToAST(call, "github.com/pkg/errors.Wrapf"):
+			if call.Ellipsis.IsValid() {
+				return
+			}
+			s, ok := j.ExprToString(call.Args[1])
+			if !ok {
+				j.Errorf(call, "should use fmt.Errorf with %%w instead of errors.Wrapf")
+				seen[call] = true
+				return
+			}
+			format, args = s, call.Args[2:]
+		default:
+			return
+		}
+		seen[call] = true
+		args = append(args[:len(args):len(args)], call.Args[0])
+		j.Errorf(call, "should use fmt.Errorf(%s, %s) instead of %s",
+			strconv.Quote(format+": %w"), j.RenderArgs(args), j.Render(call))
+	}
+	fn := func(node ast.Node) bool {
+		stmt, ok := node.(*ast.IfStmt)
+		if !ok {
+			return true
+		}
+		objs := nonNil(stmt.Cond)
+		if len(objs) == 0 {
+			return true
+		}
+		// The variables may no longer be non-nil by the time
+		// errors.Wrap is called if they're modified after the check.
+		w := written(stmt.Cond)
+		for obj := range written(stmt.Body) {
+			w[obj] = true
+		}
+		var unmodified []types.Object
+		for _, obj := range objs {
+			if !w[obj] {
+				unmodified = append(unmodified, obj)
+			}
+		}
+		objs = unmodified
+		if len(objs) == 0 {
+			return true
+		}
+		ast.Inspect(stmt.Body, func(node ast.Node) bool {
+			if call, ok := node.(*ast.CallExpr); ok {
+				check(call, objs)
+			}
+			return true
+		})
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) LintContextInScope(j *lint.Job) {
+	// contextInScope returns the name of a local variable of type
+	// context.Context that is in scope at pos.
+	contextInScope := func(f *ast.File, pos token.Pos) (string, bool) {
+		fileScope := j.Program.Info.Scopes[f]
+		if fileScope == nil {
+			return "", false
+		}
+		for scope := fileScope.Innermost(pos); scope != nil && scope != fileScope; scope = scope.Parent() {
+			for _, name := range scope.Names() {
+				obj, ok := scope.Lookup(name).(*types.Var)
+				if !ok || name == "_" || types.TypeString(obj.Type(), nil) != "context.Context" {
+					continue
+				}
+				if _, found := scope.LookupParent(name, pos); found == obj {
+					return name, true
+				}
+			}
+		}
+		return "", false
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			for _, arg := range call.Args {
+				if !j.IsCallToAST(arg, "context.TODO") {
+					continue
+				}
+				if ctx, ok := contextInScope(f, arg.Pos()); ok {
+					j.Errorf(arg, "should pass %s instead of context.TODO()", ctx)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func (c *Checker) LintHTTPDefaultClient(j *lint.Job) {
+	isZeroClient := func(expr ast.Expr) bool {
+		unary, ok := expr.(*ast.UnaryExpr)
+		if !ok || unary.Op != token.AND {
+			return false
+		}
+		lit, ok := unary.X.(*ast.CompositeLit)
+		if !ok || types.TypeString(j.Program.Info.TypeOf(lit), nil) != "net/http.Client" {
+			return false
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok || (!j.IsNil(kv.Value) && !lint.IsZero(kv.Value)) {
+				return false
+			}
+		}
+		return true
+	}
+	// onlyCallsMethods reports whether all uses of obj are method
+	// calls, which don't modify the client.
+	onlyCallsMethods := func(f *ast.File, obj types.Object) bool {
+		calls, uses := 0, 0
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				sel, ok := node.Fun.(*ast.SelectorExpr)
+				if !ok {
+					break
+				}
+				if ident, ok := sel.X.(*ast.Ident); ok && j.Program.Info.Uses[ident] == obj {
+					if _, ok := j.Program.Info.ObjectOf(sel.Sel).(*types.Func); ok {
+						calls++
+					}
+				}
+			case *ast.Ident:
+				if j.Program.Info.Uses[node] == obj {
+					uses++
+				}
+			}
+			return true
+		})
+		return uses > 0 && calls == uses
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				sel, ok := node.Fun.(*ast.SelectorExpr)
+				if ok {
+					if paren, ok := sel.X.(*ast.ParenExpr); ok && isZeroClient(paren.X) {
+						j.Errorf(paren, "should use http.DefaultClient instead of %s", j.Render(paren.X))
+					}
+				}
+			case *ast.AssignStmt:
+				if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+					break
+				}
+				for i, rhs := range node.Rhs {
+					ident, ok := node.Lhs[i].(*ast.Ident)
+					if !ok || !isZeroClient(rhs) {
+						continue
+					}
+					if obj := j.Program.Info.Defs[ident]; obj != nil && onlyCallsMethods(f, obj) {
+						j.Errorf(rhs, "should use http.DefaultClient instead of %s", j.Render(rhs))
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+func (c *Checker) LintFieldByFieldStruct(j *lint.Job) {
+	// declaredStruct returns the variable declared by stmt, if stmt
+	// is of the form "var x T" or "x := T{}" and T is a struct.
+	declaredStruct := func(stmt ast.Stmt) (*ast.Ident, bool) {
+		var ident *ast.Ident
+		switch stmt := stmt.(type) {
+		case *ast.DeclStmt:
+			gen, ok := stmt.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+				return nil, false
+			}
+			spec := gen.Specs[0].(*ast.ValueSpec)
+			if len(spec.Names) != 1 || len(spec.Values) != 0 {
+				return nil, false
+			}
+			ident = spec.Names[0]
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+				return nil, false
+			}
+			lit, ok := stmt.Rhs[0].(*ast.CompositeLit)
+			if !ok || len(lit.Elts) != 0 {
+				return nil, false
+			}
+			ident, ok = stmt.Lhs[0].(*ast.Ident)
+			if !ok {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+		obj := j.Program.Info.Defs[ident]
+		if obj == nil {
+			return nil, false
+		}
+		if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+			return nil, false
+		}
+		return ident, true
+	}
+	refersTo := func(node ast.Node, obj types.Object) bool {
+		found := false
+		ast.Inspect(node, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && j.Program.Info.ObjectOf(ident) == obj {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+	// fieldAssignment returns the name of the field of obj that stmt
+	// assigns to, if stmt is of the form "x.f = expr".
+	fieldAssignment := func(stmt ast.Stmt, obj types.Object) (string, bool) {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return "", false
+		}
+		sel, ok := assign.Lhs[0].(*ast.SelectorExpr)
+		if !ok {
+			return "", false
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || j.Program.Info.ObjectOf(ident) != obj {
+			return "", false
+		}
+		// Fields of embedded structs can't be set in a composite
+		// literal of the outer struct.
+		if selection := j.Program.Info.Selections[sel]; selection == nil || len(selection.Index()) != 1 {
+			return "", false
+		}
+		if refersTo(assign.Rhs[0], obj) {
+			return "", false
+		}
+		return sel.Sel.Name, true
+	}
+	// passes reports whether stmt passes x or &x to a function.
+	passes := func(stmt ast.Stmt, obj types.Object) bool {
+		found := false
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return !found
+			}
+			for _, arg := range call.Args {
+				if unary, ok := arg.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+					arg = unary.X
+				}
+				if ident, ok := arg.(*ast.Ident); ok && j.Program.Info.ObjectOf(ident) == obj {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	}
+	countUses := func(f *ast.File, obj types.Object) int {
+		n := 0
+		ast.Inspect(f, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && j.Program.Info.Uses[ident] == obj {
+				n++
+			}
+			return true
+		})
+		return n
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			block, ok := node.(*ast.BlockStmt)
+			if !ok {
+				return true
+			}
+			for i, stmt := range block.List {
+				ident, ok := declaredStruct(stmt)
+				if !ok {
+					continue
+				}
+				obj := j.Program.Info.Defs[ident]
+				seen := map[string]bool{}
+				k := i + 1
+				for ; k < len(block.List); k++ {
+					field, ok := fieldAssignment(block.List[k], obj)
+					if !ok || seen[field] {
+						break
+					}
+					seen[field] = true
+				}
+				if len(seen) == 0 || k == len(block.List) || !passes(block.List[k], obj) {
+					continue
+				}
+				// The variable must not be used after being passed.
+				if countUses(f, obj) != len(seen)+1 {
+					continue
+				}
+				j.Errorf(stmt, "should construct %s with a composite literal where it is passed, instead of setting its fields one by one", ident.Name)
+			}
+			return true
+		})
+	}
+}
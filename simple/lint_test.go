@@ -7,5 +7,7 @@ import (
 )
 
 func TestAll(t *testing.T) {
-	testutil.TestAll(t, NewChecker(), "")
+	c := NewChecker()
+	c.HTTPDefaultClient = true
+	testutil.TestAll(t, c, "")
 }
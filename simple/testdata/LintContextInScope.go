@@ -0,0 +1,31 @@
+package pkg
+
+import "context"
+
+func use(ctx context.Context) {}
+
+func fn1(ctx context.Context) {
+	use(context.TODO()) // MATCH /should pass ctx instead of context.TODO\(\)/
+	use(context.Background())
+	go use(context.Background())
+	defer use(context.Background())
+	detached, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	use(detached)
+	use(ctx)
+	func() {
+		use(context.TODO()) // MATCH /should pass ctx instead of context.TODO\(\)/
+	}()
+}
+
+func fn2() {
+	use(context.TODO())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	use(ctx)
+	use(context.TODO()) // MATCH /should pass ctx instead of context.TODO\(\)/
+}
+
+func fn3(_ context.Context) {
+	use(context.TODO())
+}
@@ -0,0 +1,10 @@
+package pkg
+
+import "github.com/pkg/errors"
+
+func fn(err error) error {
+	if err != nil {
+		return errors.Wrap(err, "reading config")
+	}
+	return nil
+}
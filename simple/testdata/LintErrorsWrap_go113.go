@@ -0,0 +1,57 @@
+package pkg
+
+import "github.com/pkg/errors"
+
+func fn(err error, name string, msg string) error {
+	if err != nil {
+		return errors.Wrap(err, "reading config") // MATCH /should use fmt.Errorf\("reading config: %w", err\) instead of errors.Wrap/
+	}
+	if err != nil && name != "" {
+		return errors.Wrapf(err, "reading config %s", name) // MATCH /should use fmt.Errorf\("reading config %s: %w", name, err\) instead of errors.Wrapf/
+	}
+	if nil != err {
+		return errors.Wrap(err, "100% broken") // MATCH /fmt.Errorf\("100%% broken: %w", err\)/
+	}
+	if err != nil {
+		return errors.Wrap(err, msg) // MATCH /should use fmt.Errorf with %w instead of errors.Wrap \(/
+	}
+	if err != nil {
+		if name != "" {
+			return errors.Wrap(err, "nested") // MATCH /fmt.Errorf\("nested: %w", err\)/
+		}
+	}
+
+	// err may be nil, and errors.Wrap returns nil then.
+	return errors.Wrap(err, "reading config")
+}
+
+func fn2(err, other error) error {
+	if other != nil {
+		return errors.Wrap(err, "wrong error")
+	}
+	return nil
+}
+
+func cleanup() error { return nil }
+
+func fn3(err error) error {
+	if err != nil {
+		err = cleanup()
+		return errors.Wrap(err, "cleanup")
+	}
+	if err != nil {
+		setErr(&err)
+		return errors.Wrap(err, "cleanup")
+	}
+	if err != nil {
+		for _, e := range []error{nil} {
+			if e == nil {
+				return errors.Wrap(err, "cleanup")
+			}
+			err = e
+		}
+	}
+	return nil
+}
+
+func setErr(err *error) {}
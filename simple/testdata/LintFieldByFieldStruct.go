@@ -0,0 +1,51 @@
+package pkg
+
+type Options struct {
+	Name  string
+	Count int
+}
+
+type Outer struct {
+	Options
+	Extra bool
+}
+
+func open(opts Options) {}
+
+func openPtr(opts *Options) {}
+
+func openOuter(o Outer) {}
+
+func fn1(name string) {
+	var opts Options // MATCH /should construct opts with a composite literal/
+	opts.Name = name
+	opts.Count = 1
+	open(opts)
+
+	opts2 := Options{} // MATCH /should construct opts2 with a composite literal/
+	opts2.Count = 2
+	openPtr(&opts2)
+
+	var opts3 Options
+	opts3.Name = name
+	opts3.Count = len(opts3.Name)
+	open(opts3)
+
+	var opts4 Options
+	opts4.Name = name
+	open(opts4)
+	opts4.Count = 1
+	open(opts4)
+
+	var opts5 Options
+	opts5.Name = name
+	opts5.Name = name + name
+	open(opts5)
+
+	var o Outer
+	o.Name = name
+	openOuter(o)
+
+	var opts6 Options
+	open(opts6)
+}
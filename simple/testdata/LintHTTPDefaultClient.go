@@ -0,0 +1,23 @@
+package pkg
+
+import "net/http"
+
+func fn1() {
+	c := &http.Client{} // MATCH /should use http.DefaultClient instead of &http.Client{}/
+	c.Get("a")
+	c.Get("b")
+
+	(&http.Client{Transport: nil}).Get("c") // MATCH /should use http.DefaultClient instead of &http.Client{Transport: nil}/
+
+	c2 := &http.Client{}
+	c2.Timeout = 1
+	c2.Get("d")
+
+	c3 := &http.Client{Timeout: 5}
+	c3.Get("e")
+
+	c4 := &http.Client{}
+	fn2(c4)
+}
+
+func fn2(c *http.Client) {}
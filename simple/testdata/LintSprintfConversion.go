@@ -0,0 +1,33 @@
+package pkg
+
+import "fmt"
+
+type T1 int
+
+func (T1) String() string { return "" }
+
+func fn() {
+	var i int
+	var i32 int32
+	var i64 int64
+	var u64 uint64
+	var b bool
+	var s string
+	var t1 T1
+	_ = fmt.Sprintf("%d", i)   // MATCH /should use strconv\.Itoa\(i\) instead of fmt\.Sprintf\("%d", i\)/
+	_ = fmt.Sprintf("%v", i)   // MATCH "should use strconv.Itoa(i) instead"
+	_ = fmt.Sprintf("%d", i64) // MATCH "should use strconv.FormatInt(i64, 10) instead"
+	_ = fmt.Sprintf("%d", u64) // MATCH "should use strconv.FormatUint(u64, 10) instead"
+	_ = fmt.Sprintf("%t", b)   // MATCH "should use strconv.FormatBool(b) instead"
+	_ = fmt.Sprint(i)          // MATCH "should use strconv.Itoa(i) instead of fmt.Sprint(i)"
+	_ = fmt.Sprint(b)          // MATCH "should use strconv.FormatBool(b) instead"
+	_ = fmt.Sprintf("%v", s)   // MATCH "the argument is already a string, there's no need to use fmt.Sprintf"
+
+	_ = fmt.Sprintf("%d", i32)
+	_ = fmt.Sprintf("%x", i)
+	_ = fmt.Sprintf("%5d", i)
+	_ = fmt.Sprintf("%d", t1)
+	_ = fmt.Sprint(t1)
+	_ = fmt.Sprint(i, b)
+	_ = fmt.Sprintf("%d %t", i, b)
+}
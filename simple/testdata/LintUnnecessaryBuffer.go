@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+func fn1(s string, w io.Writer) []string {
+	var buf1 bytes.Buffer
+	buf1.WriteString(s)
+	a := buf1.String() // MATCH /should use s directly instead of writing it to a bytes.Buffer first/
+
+	var sb1 strings.Builder
+	sb1.WriteString("foo")
+	sb1.WriteString("bar")
+	b := sb1.String() // MATCH /should use "foo" \+ "bar" instead of a strings.Builder/
+
+	sb2 := strings.Builder{}
+	sb2.WriteString("foo")
+	sb2.WriteString(s)
+	c := sb2.String()
+
+	var buf2 bytes.Buffer
+	buf2.WriteString(s)
+	d := buf2.String()
+	buf2.WriteString(s)
+
+	var buf3 bytes.Buffer
+	for i := 0; i < 2; i++ {
+		buf3.WriteString(s)
+	}
+	e := buf3.String()
+
+	var buf4 bytes.Buffer
+	buf4.WriteString(s)
+	io.WriteString(&buf4, s) // MATCH /should use buf4.WriteString\(s\) instead of io.WriteString\(&buf4, s\)/
+	f := buf4.String()
+
+	io.WriteString(w, s)
+	return []string{a, b, c, d, e, f}
+}
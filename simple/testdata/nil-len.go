@@ -62,3 +62,21 @@ func fn() {
 	if s != nil && len(s) != len(ch) { // nil check is not redundant here
 	}
 }
+
+type T struct {
+	s []int
+	m map[int]int
+}
+
+type S []int
+
+func fn2(t, u T, ns S) {
+	if t.s == nil || len(t.s) == 0 { // MATCH /should omit nil check/
+	}
+	if t.m != nil && len(t.m) > 0 { // MATCH /should omit nil check/
+	}
+	if ns == nil || len(ns) == 0 { // MATCH /should omit nil check/
+	}
+	if t.s == nil || len(u.s) == 0 { // different variables
+	}
+}
@@ -0,0 +1,465 @@
+package staticcheck
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+// A buildExpr is a parsed build constraint, either from a //go:build
+// line or from one or more +build lines.
+type buildExpr interface {
+	// eval reports whether the constraint is satisfied when the
+	// tags for which ok returns true are set.
+	eval(ok func(tag string) bool) bool
+	String() string
+}
+
+type (
+	tagExpr struct{ tag string }
+	notExpr struct{ x buildExpr }
+	andExpr struct{ x, y buildExpr }
+	orExpr  struct{ x, y buildExpr }
+)
+
+func (x *tagExpr) eval(ok func(string) bool) bool { return ok(x.tag) }
+func (x *notExpr) eval(ok func(string) bool) bool { return !x.x.eval(ok) }
+func (x *andExpr) eval(ok func(string) bool) bool { return x.x.eval(ok) && x.y.eval(ok) }
+func (x *orExpr) eval(ok func(string) bool) bool  { return x.x.eval(ok) || x.y.eval(ok) }
+
+func (x *tagExpr) String() string { return x.tag }
+func (x *notExpr) String() string {
+	switch x.x.(type) {
+	case *andExpr, *orExpr:
+		return "!(" + x.x.String() + ")"
+	}
+	return "!" + x.x.String()
+}
+func (x *andExpr) String() string { return andArg(x.x) + " && " + andArg(x.y) }
+func (x *orExpr) String() string  { return orArg(x.x) + " || " + orArg(x.y) }
+
+func andArg(x buildExpr) string {
+	if _, ok := x.(*orExpr); ok {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+func orArg(x buildExpr) string {
+	if _, ok := x.(*andExpr); ok {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+func and(x, y buildExpr) buildExpr {
+	if x == nil {
+		return y
+	}
+	return &andExpr{x, y}
+}
+
+func or(x, y buildExpr) buildExpr {
+	if x == nil {
+		return y
+	}
+	return &orExpr{x, y}
+}
+
+// isGoBuild reports whether the comment is a //go:build line.
+func isGoBuild(text string) bool {
+	if !strings.HasPrefix(text, "//go:build") {
+		return false
+	}
+	text = text[len("//go:build"):]
+	return text == "" || text[0] == ' ' || text[0] == '\t'
+}
+
+// isPlusBuild reports whether the comment is a +build line.
+func isPlusBuild(text string) bool {
+	if !strings.HasPrefix(text, "//") {
+		return false
+	}
+	text = strings.TrimSpace(text[len("//"):])
+	if !strings.HasPrefix(text, "+build") {
+		return false
+	}
+	text = text[len("+build"):]
+	return text == "" || text[0] == ' ' || text[0] == '\t'
+}
+
+func isValidTag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBuildConstraint parses a //go:build or +build line.
+func parseBuildConstraint(text string) (buildExpr, error) {
+	if isGoBuild(text) {
+		return parseGoBuild(strings.TrimSpace(text[len("//go:build"):]))
+	}
+	if isPlusBuild(text) {
+		text = strings.TrimSpace(text[len("//"):])
+		return parsePlusBuild(text[len("+build"):]), nil
+	}
+	return nil, errors.New("not a build constraint")
+}
+
+// parsePlusBuild parses the arguments of a +build line: a
+// space-separated list of options, each a comma-separated list of
+// possibly negated tags. Like go/build, it treats invalid options as
+// the tag "ignore", which is never set.
+func parsePlusBuild(text string) buildExpr {
+	var x buildExpr
+	for _, field := range strings.Fields(text) {
+		var y buildExpr
+		for _, tag := range strings.Split(field, ",") {
+			var z buildExpr
+			neg := strings.HasPrefix(tag, "!")
+			if neg {
+				tag = tag[1:]
+			}
+			if !isValidTag(tag) {
+				z = &tagExpr{"ignore"}
+			} else if neg {
+				z = &notExpr{&tagExpr{tag}}
+			} else {
+				z = &tagExpr{tag}
+			}
+			y = and(y, z)
+		}
+		x = or(x, y)
+	}
+	if x == nil {
+		x = &tagExpr{"ignore"}
+	}
+	return x
+}
+
+// goBuildParser is a recursive descent parser for the expressions of
+// //go:build lines.
+type goBuildParser struct {
+	s   string
+	tok string
+	pos int
+}
+
+func parseGoBuild(text string) (x buildExpr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if perr, ok := r.(goBuildError); ok {
+				x, err = nil, perr
+				return
+			}
+			panic(r)
+		}
+	}()
+	p := &goBuildParser{s: text}
+	x = p.or()
+	if p.tok != "" {
+		panic(goBuildError(fmt.Sprintf("unexpected token %q", p.tok)))
+	}
+	return x, nil
+}
+
+type goBuildError string
+
+func (err goBuildError) Error() string { return string(err) }
+
+func (p *goBuildParser) or() buildExpr {
+	x := p.and()
+	for p.tok == "||" {
+		x = &orExpr{x, p.and()}
+	}
+	return x
+}
+
+func (p *goBuildParser) and() buildExpr {
+	x := p.not()
+	for p.tok == "&&" {
+		x = &andExpr{x, p.not()}
+	}
+	return x
+}
+
+func (p *goBuildParser) not() buildExpr {
+	p.lex()
+	if p.tok == "!" {
+		p.lex()
+		if p.tok == "!" {
+			panic(goBuildError("double negation not allowed"))
+		}
+		return &notExpr{p.atom()}
+	}
+	return p.atom()
+}
+
+func (p *goBuildParser) atom() buildExpr {
+	if p.tok == "(" {
+		x := p.or()
+		if p.tok != ")" {
+			panic(goBuildError("missing close paren"))
+		}
+		p.lex()
+		return x
+	}
+	if p.tok == "" {
+		panic(goBuildError("unexpected end of expression"))
+	}
+	if !isValidTag(p.tok) {
+		panic(goBuildError(fmt.Sprintf("unexpected token %q", p.tok)))
+	}
+	x := &tagExpr{p.tok}
+	p.lex()
+	return x
+}
+
+// lex sets p.tok to the next token, or to the empty string at the
+// end of the input.
+func (p *goBuildParser) lex() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos == len(p.s) {
+		p.tok = ""
+		return
+	}
+	switch p.s[p.pos] {
+	case '(', ')', '!':
+		p.tok = p.s[p.pos : p.pos+1]
+		p.pos++
+		return
+	case '&', '|':
+		if p.pos+1 < len(p.s) && p.s[p.pos+1] == p.s[p.pos] {
+			p.tok = p.s[p.pos : p.pos+2]
+			p.pos += 2
+			return
+		}
+	}
+	end := p.pos
+	for end < len(p.s) && strings.IndexByte(" \t()!&|", p.s[end]) == -1 {
+		end++
+	}
+	if end == p.pos {
+		end++
+	}
+	p.tok = p.s[p.pos:end]
+	p.pos = end
+}
+
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"js": true, "linux": true, "nacl": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "windows": true,
+	"zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true,
+	"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+	"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+	"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+	"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+// impliedOS maps operating systems to the additional OS tag they
+// satisfy, e.g. android also satisfies linux.
+var impliedOS = map[string]string{
+	"android": "linux",
+	"illumos": "solaris",
+	"ios":     "darwin",
+}
+
+// fileNameConstraint returns the implicit build constraint of a file
+// named like name_GOOS_GOARCH.go, or nil.
+func fileNameConstraint(name string) buildExpr {
+	name = strings.TrimSuffix(filepath.Base(name), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil
+	}
+	n := len(parts)
+	if n >= 3 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return &andExpr{&tagExpr{parts[n-2]}, &tagExpr{parts[n-1]}}
+	}
+	if knownOS[parts[n-1]] || knownArch[parts[n-1]] {
+		return &tagExpr{parts[n-1]}
+	}
+	return nil
+}
+
+func constraintTags(x buildExpr, tags map[string]bool) {
+	switch x := x.(type) {
+	case *tagExpr:
+		tags[x.tag] = true
+	case *notExpr:
+		constraintTags(x.x, tags)
+	case *andExpr:
+		constraintTags(x.x, tags)
+		constraintTags(x.y, tags)
+	case *orExpr:
+		constraintTags(x.x, tags)
+		constraintTags(x.y, tags)
+	}
+}
+
+// maxFreeTags limits the number of tags other than GOOS and GOARCH
+// for which we try all combinations.
+const maxFreeTags = 10
+
+// satisfiable reports whether there is any combination of GOOS,
+// GOARCH and other build tags under which x evaluates to true. The
+// second result is false if there were too many tags to decide.
+func satisfiable(x buildExpr) (sat bool, ok bool) {
+	tags := map[string]bool{}
+	constraintTags(x, tags)
+
+	// The empty string stands for any OS or architecture not
+	// mentioned in the constraint.
+	oses := []string{""}
+	arches := []string{""}
+	var free []string
+	for tag := range tags {
+		switch {
+		case knownOS[tag]:
+			oses = append(oses, tag)
+		case knownArch[tag]:
+			arches = append(arches, tag)
+		case tag == "unix":
+		default:
+			free = append(free, tag)
+		}
+	}
+	for os, parent := range impliedOS {
+		if tags[parent] && !tags[os] {
+			oses = append(oses, os)
+		}
+	}
+	if tags["unix"] {
+		oses = append(oses, "linux")
+	}
+	if len(free) > maxFreeTags {
+		return false, false
+	}
+	sort.Strings(free)
+
+	for _, goos := range oses {
+		for _, goarch := range arches {
+			for bits := 0; bits < 1<<uint(len(free)); bits++ {
+				ok := func(tag string) bool {
+					switch {
+					case knownOS[tag]:
+						return tag == goos || impliedOS[goos] == tag
+					case knownArch[tag]:
+						return tag == goarch
+					case tag == "unix":
+						return unixOS[goos]
+					}
+					i := sort.SearchStrings(free, tag)
+					return bits&(1<<uint(i)) != 0
+				}
+				if x.eval(ok) {
+					return true, true
+				}
+			}
+		}
+	}
+	return false, true
+}
+
+// equivalentConstraints reports whether x and y evaluate to the same
+// value for all combinations of tags. The second result is false if
+// there were too many tags to decide.
+func equivalentConstraints(x, y buildExpr) (eq bool, ok bool) {
+	m := map[string]bool{}
+	constraintTags(x, m)
+	constraintTags(y, m)
+	if len(m) > maxFreeTags {
+		return false, false
+	}
+	var tags []string
+	for tag := range m {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for bits := 0; bits < 1<<uint(len(tags)); bits++ {
+		ok := func(tag string) bool {
+			i := sort.SearchStrings(tags, tag)
+			return bits&(1<<uint(i)) != 0
+		}
+		if x.eval(ok) != y.eval(ok) {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// excludedFiles parses the headers of Go files that live next to
+// the loaded files but were excluded from the build, usually because
+// of their build constraints. The files are read through ctx, the
+// build context the program was loaded with. Files that can't be
+// read or parsed are skipped.
+func excludedFiles(ctx *build.Context, fset *token.FileSet, files []*ast.File) []*ast.File {
+	loaded := map[string]bool{}
+	dirs := map[string]bool{}
+	for _, f := range files {
+		name := fset.File(f.Pos()).Name()
+		loaded[name] = true
+		dirs[filepath.Dir(name)] = true
+	}
+	var out []*ast.File
+	for dir := range dirs {
+		fis, err := buildutil.ReadDir(ctx, dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range fis {
+			name := buildutil.JoinPath(ctx, dir, fi.Name())
+			if fi.IsDir() || !strings.HasSuffix(name, ".go") || loaded[name] {
+				continue
+			}
+			if f := parseFileHeader(ctx, fset, name); f != nil {
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+func parseFileHeader(ctx *build.Context, fset *token.FileSet, name string) *ast.File {
+	rc, err := buildutil.OpenFile(ctx, name)
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	f, err := parser.ParseFile(fset, name, rc, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	return f
+}
@@ -0,0 +1,101 @@
+package staticcheck
+
+import "github.com/gm42/go-tools/lint"
+
+// checks describes the checks of the Checker for the check registry.
+// Their titles and texts come from the generated documentation.
+var checks = []*lint.Check{
+	{ID: "SA1000", SSA: true},
+	{ID: "SA1001"},
+	{ID: "SA1002", SSA: true},
+	{ID: "SA1003", SSA: true},
+	{ID: "SA1004"},
+	{ID: "SA1005"},
+	{ID: "SA1006"},
+	{ID: "SA1007", SSA: true},
+	{ID: "SA1008"},
+	{ID: "SA1010", SSA: true},
+	{ID: "SA1011", SSA: true},
+	{ID: "SA1012"},
+	{ID: "SA1013"},
+	{ID: "SA1014", SSA: true},
+	{ID: "SA1015", SSA: true},
+	{ID: "SA1016"},
+	{ID: "SA1017", SSA: true},
+	{ID: "SA1018", SSA: true},
+	{ID: "SA1019"},
+	{ID: "SA1020", SSA: true},
+	{ID: "SA1021", SSA: true},
+	{ID: "SA1022", SSA: true},
+	{ID: "SA1023", SSA: true},
+	{ID: "SA1024", SSA: true},
+	{ID: "SA1025"},
+	{ID: "SA1026", MinGoVersion: 13, SSA: true},
+	{ID: "SA1027"},
+	{ID: "SA1028", SSA: true},
+
+	{ID: "SA2000"},
+	{ID: "SA2001"},
+	{ID: "SA2002", SSA: true},
+	{ID: "SA2003", SSA: true},
+	{ID: "SA2004"},
+
+	{ID: "SA3000"},
+	{ID: "SA3001"},
+	{ID: "SA3002", SSA: true},
+
+	{ID: "SA4000"},
+	{ID: "SA4001"},
+	{ID: "SA4002", SSA: true},
+	{ID: "SA4003"},
+	{ID: "SA4004"},
+	{ID: "SA4005", SSA: true},
+	{ID: "SA4006", SSA: true},
+	{ID: "SA4008", SSA: true},
+	{ID: "SA4009", SSA: true},
+	{ID: "SA4010", SSA: true},
+	{ID: "SA4011"},
+	{ID: "SA4012", SSA: true},
+	{ID: "SA4013"},
+	{ID: "SA4014"},
+	{ID: "SA4015", SSA: true},
+	{ID: "SA4016", SSA: true},
+	{ID: "SA4017", SSA: true},
+	{ID: "SA4018"},
+	{ID: "SA4019"},
+
+	{ID: "SA5000", SSA: true},
+	{ID: "SA5001", SSA: true},
+	{ID: "SA5002"},
+	{ID: "SA5003"},
+	{ID: "SA5004"},
+	{ID: "SA5005", SSA: true},
+	{ID: "SA5007", SSA: true},
+	{ID: "SA5008"},
+	{ID: "SA5009"},
+	{ID: "SA5010", SSA: true},
+	{ID: "SA5011"},
+	{ID: "SA5012"},
+
+	{ID: "SA6000", SSA: true},
+	{ID: "SA6001", SSA: true},
+	{ID: "SA6002", SSA: true},
+	{ID: "SA6003", SSA: true},
+
+	{ID: "SA9001"},
+	{ID: "SA9002"},
+	{ID: "SA9003", SSA: true},
+	{ID: "SA9004"},
+	{ID: "SA9005"},
+	{ID: "SA9006"},
+
+	{ID: "ST1000", Severity: lint.SeverityWarning},
+	{ID: "ST1001", Severity: lint.SeverityWarning},
+}
+
+func init() {
+	for _, c := range checks {
+		c.Documentation = *docs[c.ID]
+	}
+	lint.Register(checks...)
+}
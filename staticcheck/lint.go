@@ -4,17 +4,19 @@ package staticcheck // import "github.com/gm42/go-tools/staticcheck"
 import (
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/constant"
 	"go/token"
 	"go/types"
 	htmltemplate "html/template"
+	"math"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	texttemplate "text/template"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gm42/go-tools/functions"
 	"github.com/gm42/go-tools/gcsizes"
@@ -76,6 +78,110 @@ func checkValidHostPort(arg int) CallCheck {
 	}
 }
 
+func errorsAsTarget(call *Call) {
+	const msg = "second argument to errors.As must be a non-nil pointer to either a type that implements error, or to any interface type"
+	v := call.Args[1].Value.Value
+	if k, ok := v.(*ssa.Const); ok && k.IsNil() {
+		call.Args[1].Invalid(msg)
+		return
+	}
+	if _, ok := v.Type().Underlying().(*types.Interface); ok {
+		// We don't know the dynamic type
+		return
+	}
+	ptr, ok := v.Type().Underlying().(*types.Pointer)
+	if !ok {
+		call.Args[1].Invalid(msg)
+		return
+	}
+	if _, ok := ptr.Elem().Underlying().(*types.Interface); ok {
+		return
+	}
+	if !isError(ptr.Elem()) {
+		call.Args[1].Invalid(msg)
+	}
+}
+
+func errorsIs(call *Call) {
+	if target, ok := call.Args[1].Value.Value.(*ssa.Call); ok {
+		switch lint.CallName(target.Common()) {
+		case "errors.New", "fmt.Errorf":
+			call.Args[1].Invalid("errors.Is compares against a newly constructed error, which no other error can be equal to; compare against a sentinel error instead")
+		}
+	}
+
+	v := call.Args[0].Value.Value
+	if extract, ok := v.(*ssa.Extract); ok {
+		v = extract.Tuple
+	}
+	source, ok := v.(*ssa.Call)
+	if !ok {
+		return
+	}
+	callee := source.Common().StaticCallee()
+	if callee == nil {
+		return
+	}
+	if name := formatsErrorsWithoutWrapping(call.Job, callee); name != "" {
+		call.Invalid(fmt.Sprintf("%s formats errors with %s instead of wrapping them with %%w, so errors.Is can't find them", callee.Name(), name))
+	}
+}
+
+// formatsErrorsWithoutWrapping checks whether fn returns errors
+// created by fmt.Errorf that include another error, formatted with
+// %v or %s instead of being wrapped with %w. It returns the offending
+// verb, or the empty string.
+func formatsErrorsWithoutWrapping(j *lint.Job, fn *ssa.Function) string {
+	if fn.Pkg == nil || fn.Syntax() == nil {
+		return ""
+	}
+	pkg := j.Program.Prog.AllPackages[fn.Pkg.Pkg]
+	if pkg == nil {
+		return ""
+	}
+	var verb string
+	ast.Inspect(fn.Syntax(), func(node ast.Node) bool {
+		if verb != "" {
+			return false
+		}
+		if _, ok := node.(*ast.FuncLit); ok && node != fn.Syntax() {
+			return false
+		}
+		ret, ok := node.(*ast.ReturnStmt)
+		if !ok {
+			return true
+		}
+		for _, res := range ret.Results {
+			call, ok := res.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			printf, verbs, args, ok := printfCall(&pkg.Info, call)
+			if !ok || printf.FullName() != "fmt.Errorf" {
+				continue
+			}
+			var unwrapped string
+			for _, v := range verbs {
+				if v.verb == 'w' {
+					unwrapped = ""
+					break
+				}
+				if (v.verb == 'v' || v.verb == 's') && v.arg < len(args) {
+					if typ := pkg.Info.TypeOf(args[v.arg]); typ != nil && isError(typ) {
+						unwrapped = "%" + string(v.verb)
+					}
+				}
+			}
+			if unwrapped != "" {
+				verb = unwrapped
+				return false
+			}
+		}
+		return true
+	})
+	return verb
+}
+
 var (
 	checkRegexpRules = map[string]CallCheck{
 		"regexp.MustCompile": validRegexp,
@@ -113,11 +219,10 @@ var (
 
 	checkSyncPoolSizeRules = map[string]CallCheck{
 		"(*sync.Pool).Put": func(call *Call) {
-			// TODO(dh): allow users to pass in a custom build environment
-			sizes := gcsizes.ForArch(build.Default.GOARCH)
+			sizes := call.Job.Program.Sizes
 			arg := call.Args[0]
 			typ := arg.Value.Value.Type()
-			if !types.IsInterface(typ) && sizes.Sizeof(typ) > sizes.WordSize {
+			if !types.IsInterface(typ) && sizes.Sizeof(typ) > sizes.Sizeof(types.Typ[types.Uintptr]) {
 				arg.Invalid("argument should be one word large or less to avoid allocations")
 			}
 		},
@@ -191,6 +296,11 @@ var (
 		},
 	}
 
+	checkErrorsRules = map[string]CallCheck{
+		"errors.As": errorsAsTarget,
+		"errors.Is": errorsIs,
+	}
+
 	checkRegexpMatchLoopRules = map[string]CallCheck{
 		"regexp.Match":       loopedRegexp("regexp.Match"),
 		"regexp.MatchReader": loopedRegexp("regexp.MatchReader"),
@@ -198,8 +308,16 @@ var (
 	}
 )
 
+//go:generate go run ../internal/gendocs/gendocs.go -pkg staticcheck -o docs.go ../cmd/staticcheck/docs/checks
+
 type Checker struct {
 	CheckGenerated bool
+	// DisableStyle disables all stylistic checks (ST1???).
+	DisableStyle bool
+	// Archs are the architectures for which SA2004 verifies the
+	// alignment of 64-bit atomic operations. If nil, all 32-bit
+	// architectures are checked.
+	Archs          []string
 	funcDescs      *functions.Descriptions
 	deprecatedObjs map[types.Object]string
 	nodeFns        map[ast.Node]*ssa.Function
@@ -210,7 +328,7 @@ func NewChecker() *Checker {
 }
 
 func (c *Checker) Funcs() map[string]lint.Func {
-	return map[string]lint.Func{
+	fns := map[string]lint.Func{
 		"SA1000": c.callChecker(checkRegexpRules),
 		"SA1001": c.CheckTemplate,
 		"SA1002": c.callChecker(checkTimeParseRules),
@@ -236,14 +354,20 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA1022": c.CheckFlagUsage,
 		"SA1023": c.CheckWriterBufferModified,
 		"SA1024": c.callChecker(checkUniqueCutsetRules),
+		"SA1025": c.CheckPrintfVerbs,
+		"SA1026": c.callChecker(checkErrorsRules),
+		"SA1027": c.CheckUnsafePointer,
+		"SA1028": c.CheckFlagOrder,
 
 		"SA2000": c.CheckWaitgroupAdd,
 		"SA2001": c.CheckEmptyCriticalSection,
 		"SA2002": c.CheckConcurrentTesting,
 		"SA2003": c.CheckDeferLock,
+		"SA2004": c.CheckAtomics,
 
 		"SA3000": c.CheckTestMainExit,
 		"SA3001": c.CheckBenchmarkN,
+		"SA3002": c.CheckTestComparisons,
 
 		"SA4000": c.CheckLhsRhsIdentical,
 		"SA4001": c.CheckIneffectiveCopy,
@@ -265,6 +389,7 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA4016": c.CheckSillyBitwiseOps,
 		"SA4017": c.CheckPureFunctions,
 		"SA4018": c.CheckSelfAssignment,
+		"SA4019": c.CheckAlwaysSameComparison,
 
 		"SA5000": c.CheckNilMaps,
 		"SA5001": c.CheckEarlyDefer,
@@ -274,6 +399,11 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA5005": c.CheckCyclicFinalizer,
 		// "SA5006": c.CheckSliceOutOfBounds,
 		"SA5007": c.CheckInfiniteRecursion,
+		"SA5008": c.CheckMapOrderDependence,
+		"SA5009": c.CheckRecover,
+		"SA5010": c.CheckChannelOps,
+		"SA5011": c.CheckSliceCapacity,
+		"SA5012": c.CheckNilGuards,
 
 		"SA6000": c.callChecker(checkRegexpMatchLoopRules),
 		"SA6001": c.CheckMapBytesKey,
@@ -285,7 +415,21 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA9001": c.CheckDubiousDeferInChannelRangeLoop,
 		"SA9002": c.CheckNonOctalFileMode,
 		"SA9003": c.CheckEmptyBranch,
+		"SA9004": c.CheckBuildConstraints,
+		"SA9005": c.CheckDeferInLoop,
+		"SA9006": c.CheckStringConversions,
+
+		"ST1000": c.CheckErrorStrings,
+		"ST1001": c.CheckErrorfRepeatedText,
 	}
+	if c.DisableStyle {
+		for id := range fns {
+			if strings.HasPrefix(id, "ST") {
+				fns[id] = nil
+			}
+		}
+	}
+	return fns
 }
 
 func (c *Checker) filterGenerated(files []*ast.File) []*ast.File {
@@ -757,6 +901,259 @@ func (c *Checker) CheckDubiousDeferInChannelRangeLoop(j *lint.Job) {
 	}
 }
 
+// maxDeferLoopIterations is the number of iterations up to which
+// we consider defers in a loop to be intentional.
+const maxDeferLoopIterations = 10
+
+func (c *Checker) CheckDeferInLoop(j *lint.Job) {
+	constInt := func(expr ast.Expr) (int64, bool) {
+		tv := j.Program.Info.Types[expr]
+		if tv.Value == nil {
+			return 0, false
+		}
+		return constant.Int64Val(constant.ToInt(tv.Value))
+	}
+	// iterations returns the number of times the loop runs, if it is
+	// a known constant.
+	iterations := func(loop ast.Stmt) (int64, bool) {
+		switch loop := loop.(type) {
+		case *ast.RangeStmt:
+			if lit, ok := loop.X.(*ast.CompositeLit); ok {
+				return int64(len(lit.Elts)), true
+			}
+			typ := j.Program.Info.TypeOf(loop.X)
+			if typ == nil {
+				return 0, false
+			}
+			if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			if arr, ok := typ.Underlying().(*types.Array); ok {
+				return arr.Len(), true
+			}
+			return constInt(loop.X)
+		case *ast.ForStmt:
+			// for i := lo; i < hi; i++
+			init, ok := loop.Init.(*ast.AssignStmt)
+			if !ok || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+				return 0, false
+			}
+			cond, ok := loop.Cond.(*ast.BinaryExpr)
+			if !ok {
+				return 0, false
+			}
+			post, ok := loop.Post.(*ast.IncDecStmt)
+			if !ok || post.Tok != token.INC {
+				return 0, false
+			}
+			lhs, ok := init.Lhs[0].(*ast.Ident)
+			if !ok {
+				return 0, false
+			}
+			obj := j.Program.Info.ObjectOf(lhs)
+			if ident, ok := cond.X.(*ast.Ident); !ok || j.Program.Info.ObjectOf(ident) != obj {
+				return 0, false
+			}
+			if ident, ok := post.X.(*ast.Ident); !ok || j.Program.Info.ObjectOf(ident) != obj {
+				return 0, false
+			}
+			lo, ok1 := constInt(init.Rhs[0])
+			hi, ok2 := constInt(cond.Y)
+			if !ok1 || !ok2 {
+				return 0, false
+			}
+			switch cond.Op {
+			case token.LSS:
+				return hi - lo, true
+			case token.LEQ:
+				return hi - lo + 1, true
+			}
+		}
+		return 0, false
+	}
+	// flagged reports whether defers in the loop should be flagged.
+	// Other checks take care of some kinds of loops.
+	flagged := func(loop ast.Stmt) bool {
+		switch loop := loop.(type) {
+		case *ast.RangeStmt:
+			typ := j.Program.Info.TypeOf(loop.X)
+			if typ == nil {
+				return false
+			}
+			if _, ok := typ.Underlying().(*types.Chan); ok {
+				// Flagged by SA9001
+				return false
+			}
+		case *ast.ForStmt:
+			if loop.Cond == nil {
+				// Infinite loops are handled by SA5003
+				return false
+			}
+			if j.Program.Info.Types[loop.Cond].Value != nil {
+				// Either infinite or never running
+				return false
+			}
+		}
+		n, ok := iterations(loop)
+		return !ok || n > maxDeferLoopIterations
+	}
+	var walk func(node ast.Node, inLoop bool)
+	walk = func(node ast.Node, inLoop bool) {
+		ast.Inspect(node, func(child ast.Node) bool {
+			if child == node {
+				return true
+			}
+			switch child := child.(type) {
+			case *ast.DeferStmt:
+				if inLoop {
+					j.Errorf(child, "defers in this loop only run when the surrounding function returns, accumulating resources until then; consider moving the loop body into a function")
+				}
+			case *ast.FuncLit:
+				walk(child.Body, false)
+				return false
+			case *ast.ForStmt:
+				walk(child.Body, inLoop || flagged(child))
+				return false
+			case *ast.RangeStmt:
+				walk(child.Body, inLoop || flagged(child))
+				return false
+			}
+			return true
+		})
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		walk(f, false)
+	}
+}
+
+func (c *Checker) CheckStringConversions(j *lint.Job) {
+	isString := func(T types.Type) bool {
+		basic, ok := T.Underlying().(*types.Basic)
+		return ok && basic.Info()&types.IsString != 0
+	}
+	isRunes := func(T types.Type) bool {
+		slice, ok := T.Underlying().(*types.Slice)
+		return ok && types.Identical(slice.Elem(), types.Typ[types.Rune])
+	}
+	isRune := func(T types.Type) bool {
+		return types.Identical(T, types.Typ[types.Rune])
+	}
+	// lenOf returns the operand of expr if it is a call to len.
+	lenOf := func(expr ast.Expr) (ast.Expr, bool) {
+		call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return nil, false
+		}
+		ident, ok := call.Fun.(*ast.Ident)
+		if !ok || j.Program.Info.ObjectOf(ident) != types.Universe.Lookup("len") {
+			return nil, false
+		}
+		return call.Args[0], true
+	}
+
+	// The strings that variables were converted to []rune from.
+	runes := map[types.Object]ast.Expr{}
+	runesOf := func(expr ast.Expr) (ast.Expr, bool) {
+		if s, ok := conversionArg(j, astutil.Unparen(expr), isRunes); ok {
+			return s, true
+		}
+		if ident, ok := astutil.Unparen(expr).(*ast.Ident); ok {
+			s, ok := runes[j.Program.Info.ObjectOf(ident)]
+			return s, ok
+		}
+		return nil, false
+	}
+	// checkBounds flags indices into the runes of a string that are
+	// derived from the string's length in bytes, and indices into a
+	// string that are derived from its length in runes.
+	checkBounds := func(x ast.Expr, indices ...ast.Expr) {
+		for _, index := range indices {
+			if index == nil {
+				continue
+			}
+			if bin, ok := astutil.Unparen(index).(*ast.BinaryExpr); ok && (bin.Op == token.ADD || bin.Op == token.SUB) {
+				// len(s)-1 and similar
+				index = bin.X
+			}
+			if s, ok := runesOf(x); ok {
+				if arg, ok := lenOf(index); ok && j.Render(arg) == j.Render(s) {
+					j.Errorf(index, "len(%s) is the length of %s in bytes, not in runes; use len(%s) instead", j.Render(arg), j.Render(arg), j.Render(x))
+				}
+				continue
+			}
+			if !isString(j.Program.Info.TypeOf(x)) {
+				continue
+			}
+			call, ok := astutil.Unparen(index).(*ast.CallExpr)
+			if ok && j.IsCallToAST(call, "unicode/utf8.RuneCountInString") && len(call.Args) == 1 && j.Render(call.Args[0]) == j.Render(x) {
+				j.Errorf(index, "%s is the length of %s in runes, but strings are indexed by bytes; use len(%s) instead", j.Render(call), j.Render(x), j.Render(x))
+			}
+		}
+	}
+
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, rhs := range node.Rhs {
+				s, ok := conversionArg(j, astutil.Unparen(rhs), isRunes)
+				if !ok {
+					continue
+				}
+				if ident, ok := node.Lhs[i].(*ast.Ident); ok {
+					if obj := j.Program.Info.ObjectOf(ident); obj != nil {
+						runes[obj] = s
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if arg, ok := conversionArg(j, node, isString); ok {
+				tv := j.Program.Info.Types[arg]
+				basic, ok := tv.Type.Underlying().(*types.Basic)
+				if !ok || basic.Info()&types.IsInteger == 0 || tv.Value != nil {
+					return true
+				}
+				if isRune(tv.Type) || types.Identical(tv.Type, types.Typ[types.Byte]) {
+					return true
+				}
+				j.Errorf(node, "conversion from %s to string yields a string of one rune, not a string of digits; use strconv.Itoa or fmt.Sprint to format the number, or string(rune(%s)) if the rune is intended",
+					tv.Type, j.Render(arg))
+				return true
+			}
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			fn, ok := j.Program.Info.ObjectOf(sel.Sel).(*types.Func)
+			if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "unicode" {
+				return true
+			}
+			for _, arg := range node.Args {
+				b, ok := conversionArg(j, astutil.Unparen(arg), isRune)
+				if !ok {
+					continue
+				}
+				index, ok := astutil.Unparen(b).(*ast.IndexExpr)
+				if !ok || !isString(j.Program.Info.TypeOf(index.X)) {
+					continue
+				}
+				j.Errorf(arg, "%s is a single byte of %s, not a character, which unicode.%s can't handle if the text isn't ASCII; range over %s to get its runes",
+					j.Render(b), j.Render(index.X), fn.Name(), j.Render(index.X))
+			}
+		case *ast.IndexExpr:
+			checkBounds(node.X, node.Index)
+		case *ast.SliceExpr:
+			checkBounds(node.X, node.Low, node.High, node.Max)
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
 func (c *Checker) CheckTestMainExit(j *lint.Job) {
 	fn := func(node ast.Node) bool {
 		if !isTestMain(j, node) {
@@ -992,7 +1389,99 @@ func (c *Checker) CheckUnsafePrintf(j *lint.Job) {
 	}
 }
 
+func hasStringMethod(typ types.Type) bool {
+	ms := types.NewMethodSet(typ)
+	sel := ms.Lookup(nil, "String")
+	if sel == nil {
+		return false
+	}
+	sig, ok := sel.Type().(*types.Signature)
+	if !ok || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	return types.Identical(sig.Results().At(0).Type(), types.Typ[types.String])
+}
+
+func isError(typ types.Type) bool {
+	iface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return types.Implements(typ, iface)
+}
+
+func (c *Checker) CheckPrintfVerbs(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		_, verbs, args, ok := printfCall(j.Program.Info, call)
+		if !ok {
+			return true
+		}
+		for _, verb := range verbs {
+			if verb.arg >= len(args) {
+				break
+			}
+			arg := args[verb.arg]
+			typ := j.Program.Info.TypeOf(arg)
+			if typ == nil {
+				continue
+			}
+			if verb.verb == 'T' {
+				if types.TypeString(typ, nil) == "reflect.Type" {
+					j.Errorf(arg, "%%T verb used with a reflect.Type prints the type of the reflect.Type itself; use %%v to print the type it describes")
+				}
+				continue
+			}
+			if isError(typ) || hasStringMethod(typ) {
+				switch verb.verb {
+				case 'd':
+					if basic, ok := typ.Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+						j.Errorf(arg, "%%d verb used with %s, which has %s method; did you mean %%s or %%v?",
+							j.Render(arg), stringerMethod(typ))
+					}
+				}
+				continue
+			}
+			switch verb.verb {
+			case 's':
+				basic, ok := typ.Underlying().(*types.Basic)
+				if !ok {
+					continue
+				}
+				var alt string
+				switch {
+				case basic.Info()&types.IsInteger != 0:
+					alt = "%d"
+				case basic.Info()&types.IsFloat != 0, basic.Info()&types.IsComplex != 0:
+					alt = "%g"
+				case basic.Info()&types.IsBoolean != 0:
+					alt = "%t"
+				default:
+					continue
+				}
+				j.Errorf(arg, "%%s verb used with %s of type %s; did you mean %s?", j.Render(arg), typ, alt)
+			case 'd':
+				if basic, ok := typ.Underlying().(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+					j.Errorf(arg, "%%d verb used with %s of type %s; did you mean %%s?", j.Render(arg), typ)
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+func stringerMethod(typ types.Type) string {
+	if isError(typ) {
+		return "an Error"
+	}
+	return "a String"
+}
+
 func (c *Checker) CheckEarlyDefer(j *lint.Job) {
+	flagged := map[token.Pos]bool{}
 	fn := func(node ast.Node) bool {
 		block, ok := node.(*ast.BlockStmt)
 		if !ok {
@@ -1058,12 +1547,95 @@ func (c *Checker) CheckEarlyDefer(j *lint.Job) {
 				continue
 			}
 			j.Errorf(def, "should check returned error before deferring %s", j.Render(def.Call))
+			flagged[def.Pos()] = true
 		}
 		return true
 	}
 	for _, f := range j.Program.Files {
 		ast.Inspect(f, fn)
 	}
+
+	// The pattern above only covers defers that immediately follow
+	// the call. More generally, a defer statement that dereferences a
+	// result of a call, as in defer resp.Body.Close(), panics if the
+	// call failed, unless the error has been checked by then.
+
+	// derefRoot returns the value that v is loaded from, and whether
+	// doing so dereferences it.
+	derefRoot := func(v ssa.Value) (ssa.Value, bool) {
+		deref := false
+		for {
+			switch x := v.(type) {
+			case *ssa.UnOp:
+				if x.Op != token.MUL {
+					return v, deref
+				}
+				deref = true
+				v = x.X
+			case *ssa.FieldAddr:
+				deref = true
+				v = x.X
+			case *ssa.Field:
+				v = x.X
+			default:
+				return v, deref
+			}
+		}
+	}
+	// uncheckedError returns the error result of the call that
+	// produced ext, if it hasn't been used before ins.
+	uncheckedError := func(ext *ssa.Extract, ins ssa.Instruction) (*ssa.Call, bool) {
+		call, ok := ext.Tuple.(*ssa.Call)
+		if !ok {
+			return nil, false
+		}
+		res := call.Call.Signature().Results()
+		last := res.Len() - 1
+		if ext.Index == last || !types.Identical(res.At(last).Type(), types.Universe.Lookup("error").Type()) {
+			return nil, false
+		}
+		for _, ref := range *call.Referrers() {
+			errv, ok := ref.(*ssa.Extract)
+			if !ok || errv.Index != last {
+				continue
+			}
+			used := false
+			for _, use := range *errv.Referrers() {
+				if _, ok := use.(*ssa.DebugRef); ok {
+					continue
+				}
+				if precedes(use, ins) {
+					return nil, false
+				}
+				used = true
+			}
+			return call, used
+		}
+		// The error is discarded.
+		return nil, false
+	}
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				def, ok := ins.(*ssa.Defer)
+				if !ok || flagged[def.Pos()] {
+					continue
+				}
+				ops := append([]ssa.Value{def.Call.Value}, def.Call.Args...)
+				for _, op := range ops {
+					root, deref := derefRoot(op)
+					ext, ok := root.(*ssa.Extract)
+					if !deref || !ok {
+						continue
+					}
+					if call, ok := uncheckedError(ext, def); ok {
+						j.Errorf(def, "should check the error returned by %s before deferring a call that dereferences its result", lint.CallName(call.Common()))
+						break
+					}
+				}
+			}
+		}
+	}
 }
 
 func selectorX(sel *ast.SelectorExpr) ast.Node {
@@ -1264,12 +1836,103 @@ func (c *Checker) CheckBenchmarkN(j *lint.Job) {
 	}
 }
 
-func (c *Checker) CheckIneffectiveFieldAssignments(j *lint.Job) {
-	for _, ssafn := range j.Program.InitialFunctions {
-		// fset := j.Program.SSA.Fset
-		// if fset.File(f.File.Pos()) != fset.File(ssafn.Pos()) {
-		// 	continue
-		// }
+func (c *Checker) CheckTestComparisons(j *lint.Job) {
+	// isFresh reports whether v is a freshly allocated value, as
+	// created by &T{...} or new(T), that nothing can have obtained a
+	// pointer to before the comparison cmp.
+	isFresh := func(v ssa.Value, cmp *ssa.BinOp) bool {
+		alloc, ok := v.(*ssa.Alloc)
+		if !ok {
+			return false
+		}
+		for _, ref := range *alloc.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.DebugRef, *ssa.BinOp, *ssa.UnOp:
+			case *ssa.Store:
+				if ref.Addr != alloc && !precedes(cmp, ref) {
+					return false
+				}
+			case *ssa.FieldAddr, *ssa.IndexAddr:
+				addr := ref.(ssa.Value)
+				for _, ref := range *addr.Referrers() {
+					switch ref := ref.(type) {
+					case *ssa.Store:
+						if ref.Addr != addr && !precedes(cmp, ref) {
+							return false
+						}
+					case *ssa.UnOp, *ssa.DebugRef:
+					default:
+						if !precedes(cmp, ref) {
+							return false
+						}
+					}
+				}
+			default:
+				if !precedes(cmp, ref) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for _, ssafn := range j.Program.InitialFunctions {
+		if !j.IsInTest(ssafn) {
+			continue
+		}
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				binop, ok := ins.(*ssa.BinOp)
+				if !ok || (binop.Op != token.EQL && binop.Op != token.NEQ) {
+					continue
+				}
+				if _, ok := binop.X.Type().Underlying().(*types.Pointer); !ok {
+					continue
+				}
+				if binop.X == binop.Y || (!isFresh(binop.X, binop) && !isFresh(binop.Y, binop)) {
+					continue
+				}
+				j.Errorf(binop, "this comparison is always %t because one of the pointers points to a freshly allocated value; did you mean to compare the values they point to?", binop.Op == token.NEQ)
+			}
+		}
+	}
+
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || !j.IsCallToAST(call, "reflect.DeepEqual") || len(call.Args) != 2 {
+			return true
+		}
+		T := j.Program.Info.TypeOf(call.Args[0])
+		if types.IsInterface(T) {
+			return true
+		}
+		obj, _, _ := types.LookupFieldOrMethod(T, true, nil, "Equal")
+		method, ok := obj.(*types.Func)
+		if !ok {
+			return true
+		}
+		sig := method.Type().(*types.Signature)
+		if sig.Params().Len() != 1 || sig.Results().Len() != 1 ||
+			!types.Identical(sig.Params().At(0).Type(), T) ||
+			!types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool]) {
+			return true
+		}
+		j.Errorf(call, "should use %s.Equal(%s) instead of reflect.DeepEqual, which ignores the Equal method of %s",
+			j.Render(call.Args[0]), j.Render(call.Args[1]), types.TypeString(T, nil))
+		return true
+	}
+	for _, f := range j.Program.Files {
+		if j.IsInTest(f) {
+			ast.Inspect(f, fn)
+		}
+	}
+}
+
+func (c *Checker) CheckIneffectiveFieldAssignments(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		// fset := j.Program.SSA.Fset
+		// if fset.File(f.File.Pos()) != fset.File(ssafn.Pos()) {
+		// 	continue
+		// }
 		if ssafn.Signature.Recv() == nil {
 			continue
 		}
@@ -2608,6 +3271,213 @@ func (c *Checker) CheckFlagUsage(j *lint.Job) {
 	}
 }
 
+// flagDefs maps the functions of the flag package that define flags
+// of the command line to the index of their name argument.
+var flagDefs = map[string]int{
+	"flag.Bool":        0,
+	"flag.BoolVar":     1,
+	"flag.Duration":    0,
+	"flag.DurationVar": 1,
+	"flag.Float64":     0,
+	"flag.Float64Var":  1,
+	"flag.Func":        0,
+	"flag.Int":         0,
+	"flag.IntVar":      1,
+	"flag.Int64":       0,
+	"flag.Int64Var":    1,
+	"flag.String":      0,
+	"flag.StringVar":   1,
+	"flag.TextVar":     1,
+	"flag.Uint":        0,
+	"flag.UintVar":     1,
+	"flag.Uint64":      0,
+	"flag.Uint64Var":   1,
+	"flag.Var":         1,
+}
+
+func (c *Checker) CheckFlagOrder(j *lint.Job) {
+	isInit := func(fn *ssa.Function) bool {
+		return fn.Parent() == nil && (fn.Name() == "init" || strings.HasPrefix(fn.Name(), "init#"))
+	}
+	// Package-level variables holding the pointers returned by
+	// flag.String and the like, and variables passed to
+	// flag.StringVar and the like.
+	ptrs := map[*ssa.Global]bool{}
+	vars := map[ssa.Value]bool{}
+	for _, fn := range j.Program.InitialFunctions {
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				name := lint.CallName(call.Common())
+				if _, ok := flagDefs[name]; !ok {
+					continue
+				}
+				if strings.HasSuffix(name, "Var") {
+					switch arg := call.Common().Args[0].(type) {
+					case *ssa.Global, *ssa.Alloc:
+						vars[arg] = true
+					}
+					continue
+				}
+				for _, ref := range *call.Referrers() {
+					if store, ok := ref.(*ssa.Store); ok && store.Val == call {
+						if g, ok := store.Addr.(*ssa.Global); ok {
+							ptrs[g] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	// isFlagRead reports whether ins reads the value of a flag.
+	isFlagRead := func(ins ssa.Instruction) bool {
+		load, ok := ins.(*ssa.UnOp)
+		if !ok || load.Op != token.MUL {
+			return false
+		}
+		if vars[load.X] {
+			return true
+		}
+		switch x := load.X.(type) {
+		case *ssa.Call:
+			_, ok := flagDefs[lint.CallName(x.Common())]
+			return ok
+		case *ssa.UnOp:
+			g, ok := x.X.(*ssa.Global)
+			return ok && x.Op == token.MUL && ptrs[g]
+		}
+		return false
+	}
+
+	// unconditional reports whether b runs whenever fn, a function
+	// that always runs, returns. Package-level variables are always
+	// initialized, even though SSA guards their initialization.
+	unconditional := func(fn *ssa.Function, b *ssa.BasicBlock) bool {
+		if fn.Synthetic != "" {
+			return true
+		}
+		for _, ret := range fn.Blocks {
+			if _, ok := ret.Instrs[len(ret.Instrs)-1].(*ssa.Return); ok && !b.Dominates(ret) {
+				return false
+			}
+		}
+		return true
+	}
+	// Flags defined by code that always runs, by name, and the first
+	// definition of each of them.
+	var defs []*ssa.Call
+	first := map[string]*ssa.Call{}
+	flagName := func(call *ssa.Call) string {
+		k := call.Common().Args[flagDefs[lint.CallName(call.Common())]].(*ssa.Const)
+		return constant.StringVal(k.Value)
+	}
+	for _, fn := range j.Program.InitialFunctions {
+		var parses []*ssa.Call
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				if call, ok := ins.(*ssa.Call); ok && lint.IsCallTo(call.Common(), "flag.Parse") {
+					parses = append(parses, call)
+				}
+			}
+		}
+		if isInit(fn) {
+			for _, parse := range parses {
+				j.Errorf(parse, "flag.Parse shouldn't be called during initialization, when packages that are initialized later, such as testing, haven't defined their flags yet")
+			}
+		}
+		always := isInit(fn) || (fn.Pkg.Pkg.Name() == "main" && fn.Name() == "main" && fn.Parent() == nil)
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				if isFlagRead(ins) && (isInit(fn) || len(parses) > 0) {
+					parsed := false
+					for _, parse := range parses {
+						if precedes(parse, ins) {
+							parsed = true
+							break
+						}
+					}
+					if !parsed {
+						j.Errorf(ins, "the flag is read before flag.Parse has been called, so it always has its default value")
+					}
+					continue
+				}
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				idx, ok := flagDefs[lint.CallName(call.Common())]
+				if !ok {
+					continue
+				}
+				for _, parse := range parses {
+					if reachable(parse, call) {
+						j.Errorf(call, "the flag is defined after flag.Parse has been called, so it can't be set on the command line")
+						break
+					}
+				}
+				if !always || !unconditional(fn, b) {
+					continue
+				}
+				if k, ok := call.Common().Args[idx].(*ssa.Const); ok && k.Value != nil && k.Value.Kind() == constant.String {
+					defs = append(defs, call)
+					if prev, ok := first[flagName(call)]; !ok || call.Pos() < prev.Pos() {
+						first[flagName(call)] = call
+					}
+				}
+			}
+		}
+	}
+	for _, call := range defs {
+		name := flagName(call)
+		if prev := first[name]; prev != call {
+			j.Errorf(call, "flag %q has already been defined (at %s), defining it again panics", name, j.Program.SSA.Fset.Position(prev.Pos()))
+		}
+	}
+}
+
+// precedes reports whether a always executes before b, which are
+// instructions of the same function.
+func precedes(a, b ssa.Instruction) bool {
+	if a.Block() != b.Block() {
+		return a.Block().Dominates(b.Block())
+	}
+	for _, ins := range b.Block().Instrs {
+		if ins == a {
+			return true
+		}
+		if ins == b {
+			return false
+		}
+	}
+	return false
+}
+
+// reachable reports whether b can execute after a, which are
+// instructions of the same function.
+func reachable(a, b ssa.Instruction) bool {
+	if a.Block() == b.Block() && !precedes(b, a) {
+		return true
+	}
+	seen := map[*ssa.BasicBlock]bool{}
+	queue := append([]*ssa.BasicBlock(nil), a.Block().Succs...)
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		if block == b.Block() {
+			return true
+		}
+		if seen[block] {
+			continue
+		}
+		seen[block] = true
+		queue = append(queue, block.Succs...)
+	}
+	return false
+}
+
 func unwrapFunction(val ssa.Value) *ssa.Function {
 	switch val := val.(type) {
 	case *ssa.Function:
@@ -2747,75 +3617,1622 @@ func (c *Checker) CheckEmptyBranch(j *lint.Job) {
 	}
 }
 
-func (c *Checker) CheckMapBytesKey(j *lint.Job) {
-	for _, fn := range j.Program.InitialFunctions {
-		for _, b := range fn.Blocks {
-		insLoop:
-			for _, ins := range b.Instrs {
-				// find []byte -> string conversions
-				conv, ok := ins.(*ssa.Convert)
-				if !ok || conv.Type() != types.Universe.Lookup("string").Type() {
+func (c *Checker) CheckBuildConstraints(j *lint.Job) {
+	fn := func(f *ast.File) {
+		var goBuild, plusBuild buildExpr
+		var first, goBuildComment *ast.Comment
+		for _, group := range f.Comments {
+			for _, comment := range group.List {
+				if !strings.HasPrefix(comment.Text, "//") {
 					continue
 				}
-				if s, ok := conv.X.Type().(*types.Slice); !ok || s.Elem() != types.Universe.Lookup("byte").Type() {
+				if comment.Pos() < f.Package && strings.HasPrefix(comment.Text, "// go:build") {
+					j.Errorf(comment, "build constraint must not have a space between // and go:build")
 					continue
 				}
-				refs := conv.Referrers()
-				// need at least two (DebugRef) references: the
-				// conversion and the *ast.Ident
-				if refs == nil || len(*refs) < 2 {
+				if !isGoBuild(comment.Text) && !isPlusBuild(comment.Text) {
 					continue
 				}
-				ident := false
-				// skip first reference, that's the conversion itself
-				for _, ref := range (*refs)[1:] {
-					switch ref := ref.(type) {
-					case *ssa.DebugRef:
-						if _, ok := ref.Expr.(*ast.Ident); !ok {
-							// the string seems to be used somewhere
-							// unexpected; the default branch should
-							// catch this already, but be safe
-							continue insLoop
-						} else {
-							ident = true
-						}
-					case *ssa.Lookup:
-					default:
-						// the string is used somewhere else than a
-						// map lookup
-						continue insLoop
-					}
+				if comment.Pos() > f.Package {
+					j.Errorf(comment, "build constraint after the package clause will be ignored")
+					continue
 				}
-
-				// the result of the conversion wasn't assigned to an
-				// identifier
-				if !ident {
+				if group == f.Doc {
+					j.Errorf(comment, "build constraint must be followed by a blank line, or it will be ignored")
 					continue
 				}
-				j.Errorf(conv, "m[string(key)] would be more efficient than k := string(key); m[k]")
+				expr, err := parseBuildConstraint(comment.Text)
+				if err != nil {
+					j.Errorf(comment, "malformed build constraint: %s", err)
+					continue
+				}
+				if first == nil {
+					first = comment
+				}
+				if isGoBuild(comment.Text) {
+					if goBuild != nil {
+						j.Errorf(comment, "multiple //go:build lines")
+						continue
+					}
+					goBuild = expr
+					goBuildComment = comment
+				} else if plusBuild == nil {
+					plusBuild = expr
+				} else {
+					plusBuild = &andExpr{plusBuild, expr}
+				}
+			}
+		}
+		if first == nil {
+			return
+		}
+
+		if goBuild != nil && plusBuild != nil {
+			if eq, ok := equivalentConstraints(goBuild, plusBuild); ok && !eq {
+				j.Errorf(goBuildComment, "//go:build line doesn't match +build lines: %s and %s", goBuild, plusBuild)
 			}
 		}
+		expr := goBuild
+		if expr == nil {
+			expr = plusBuild
+		}
+		if tags := fileNameConstraint(j.Program.Prog.Fset.File(f.Pos()).Name()); tags != nil {
+			expr = &andExpr{expr, tags}
+		}
+		if sat, ok := satisfiable(expr); ok && !sat {
+			j.Errorf(first, "build constraint can never be satisfied, the file will never be built")
+		}
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		fn(f)
+	}
+	for _, f := range excludedFiles(j.Program.Build, j.Program.Prog.Fset, j.Program.Files) {
+		fn(f)
 	}
 }
 
-func (c *Checker) CheckRangeStringRunes(j *lint.Job) {
-	sharedcheck.CheckRangeStringRunes(c.nodeFns, j)
+// mapOrderSinks are functions whose result depends on the order of
+// the elements of a slice passed to them.
+var mapOrderSinks = map[string]bool{
+	"reflect.DeepEqual":               true,
+	"encoding/json.Marshal":           true,
+	"encoding/json.MarshalIndent":     true,
+	"strings.Join":                    true,
+	"bytes.Join":                      true,
+	"fmt.Fprint":                      true,
+	"fmt.Fprintf":                     true,
+	"fmt.Fprintln":                    true,
+	"fmt.Print":                       true,
+	"fmt.Printf":                      true,
+	"fmt.Println":                     true,
+	"fmt.Sprint":                      true,
+	"fmt.Sprintf":                     true,
+	"fmt.Sprintln":                    true,
+	"(*encoding/json.Encoder).Encode": true,
+	"(*encoding/gob.Encoder).Encode":  true,
+	"(*encoding/xml.Encoder).Encode":  true,
+	"encoding/xml.Marshal":            true,
+	"encoding/xml.MarshalIndent":      true,
 }
 
-func (c *Checker) CheckSelfAssignment(j *lint.Job) {
-	fn := func(node ast.Node) bool {
-		assign, ok := node.(*ast.AssignStmt)
+func isHash(typ types.Type) bool {
+	ms := types.NewMethodSet(typ)
+	for _, name := range []string{"Write", "Sum", "Reset", "Size", "BlockSize"} {
+		if ms.Lookup(nil, name) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Checker) CheckMapOrderDependence(j *lint.Job) {
+	isMapRange := func(stmt ast.Stmt) (*ast.RangeStmt, bool) {
+		rng, ok := stmt.(*ast.RangeStmt)
 		if !ok {
-			return true
+			return nil, false
 		}
-		if assign.Tok != token.ASSIGN || len(assign.Lhs) != len(assign.Rhs) {
+		typ := j.Program.Info.TypeOf(rng.X)
+		if typ == nil {
+			return nil, false
+		}
+		_, ok = typ.Underlying().(*types.Map)
+		return rng, ok
+	}
+	// appendedTo returns the variables that body appends to, and
+	// that have been declared outside of it.
+	appendedTo := func(body *ast.BlockStmt) map[types.Object]bool {
+		objs := map[types.Object]bool{}
+		ast.Inspect(body, func(node ast.Node) bool {
+			assign, ok := node.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				return true
+			}
+			ident, ok := assign.Lhs[0].(*ast.Ident)
+			if !ok {
+				return true
+			}
+			call, ok := assign.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if fn, ok := call.Fun.(*ast.Ident); !ok || j.Program.Info.ObjectOf(fn) != types.Universe.Lookup("append") {
+				return true
+			}
+			obj := j.Program.Info.ObjectOf(ident)
+			if obj == nil || (obj.Pos() >= body.Pos() && obj.Pos() < body.End()) {
+				return true
+			}
+			objs[obj] = true
 			return true
+		})
+		return objs
+	}
+	mentions := func(node ast.Node, obj types.Object) bool {
+		found := false
+		ast.Inspect(node, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && j.Program.Info.ObjectOf(ident) == obj {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+	calleeName := func(call *ast.CallExpr) string {
+		var ident *ast.Ident
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			ident = fun
+		case *ast.SelectorExpr:
+			ident = fun.Sel
+		default:
+			return ""
 		}
-		for i, stmt := range assign.Lhs {
-			rlh := j.Render(stmt)
-			rrh := j.Render(assign.Rhs[i])
-			if rlh == rrh {
-				j.Errorf(assign, "self-assignment of %s to %s", rrh, rlh)
+		fn, ok := j.Program.Info.ObjectOf(ident).(*types.Func)
+		if !ok {
+			return ""
+		}
+		return fn.FullName()
+	}
+	// checkUses reports the first use of obj in stmts that depends on
+	// the order of its elements, unless the slice gets sorted first.
+	checkUses := func(obj types.Object, stmts []ast.Stmt) {
+		done := false
+		for _, stmt := range stmts {
+			ast.Inspect(stmt, func(node ast.Node) bool {
+				if done {
+					return false
+				}
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				name := calleeName(call)
+				if strings.HasPrefix(name, "sort.") || strings.HasPrefix(name, "slices.Sort") {
+					for _, arg := range call.Args {
+						if mentions(arg, obj) {
+							done = true
+							return false
+						}
+					}
+					return true
+				}
+				if !mapOrderSinks[name] {
+					return true
+				}
+				for _, arg := range call.Args {
+					if ident, ok := arg.(*ast.Ident); ok && j.Program.Info.ObjectOf(ident) == obj {
+						j.Errorf(arg, "%s is built by ranging over a map and its order is random; sort it before passing it to %s", obj.Name(), j.Render(call.Fun))
+						done = true
+						return false
+					}
+				}
+				return true
+			})
+			if done {
+				return
+			}
+		}
+	}
+	fn := func(node ast.Node) bool {
+		block, ok := node.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+		for i, stmt := range block.List {
+			rng, ok := isMapRange(stmt)
+			if !ok {
+				continue
+			}
+			for obj := range appendedTo(rng.Body) {
+				checkUses(obj, block.List[i+1:])
+			}
+			ast.Inspect(rng.Body, func(node ast.Node) bool {
+				if _, ok := node.(*ast.FuncLit); ok {
+					return false
+				}
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || (sel.Sel.Name != "Write" && sel.Sel.Name != "WriteString") {
+					return true
+				}
+				typ := j.Program.Info.TypeOf(sel.X)
+				if typ != nil && isHash(typ) {
+					j.Errorf(call, "hashing values in map iteration order, which is random, makes the hash nondeterministic; sort the keys first")
+				}
+				return true
+			})
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// resultBounds are the inclusive bounds of the results of functions
+// returning integers. math.MaxInt64 means unbounded.
+var resultBounds = map[string][2]int64{
+	"len":                            {0, math.MaxInt64},
+	"cap":                            {0, math.MaxInt64},
+	"strings.Index":                  {-1, math.MaxInt64},
+	"strings.IndexAny":               {-1, math.MaxInt64},
+	"strings.IndexByte":              {-1, math.MaxInt64},
+	"strings.IndexRune":              {-1, math.MaxInt64},
+	"strings.LastIndex":              {-1, math.MaxInt64},
+	"strings.LastIndexAny":           {-1, math.MaxInt64},
+	"strings.LastIndexByte":          {-1, math.MaxInt64},
+	"strings.Count":                  {0, math.MaxInt64},
+	"strings.Compare":                {-1, 1},
+	"bytes.Index":                    {-1, math.MaxInt64},
+	"bytes.IndexAny":                 {-1, math.MaxInt64},
+	"bytes.IndexByte":                {-1, math.MaxInt64},
+	"bytes.IndexRune":                {-1, math.MaxInt64},
+	"bytes.LastIndex":                {-1, math.MaxInt64},
+	"bytes.LastIndexAny":             {-1, math.MaxInt64},
+	"bytes.LastIndexByte":            {-1, math.MaxInt64},
+	"bytes.Count":                    {0, math.MaxInt64},
+	"bytes.Compare":                  {-1, 1},
+	"unicode/utf8.RuneCount":         {0, math.MaxInt64},
+	"unicode/utf8.RuneCountInString": {0, math.MaxInt64},
+	"unicode/utf8.RuneLen":           {-1, 4},
+}
+
+// resultCanonicalizers are functions returning strings that are
+// fixed points of themselves; comparing their results to constants
+// that aren't can never be true.
+var resultCanonicalizers = map[string]func(string) string{
+	"strings.ToLower":   strings.ToLower,
+	"strings.ToUpper":   strings.ToUpper,
+	"strings.TrimSpace": strings.TrimSpace,
+}
+
+// compareBounds evaluates x op k for all x in [lo, hi]. It returns
+// whether the outcome is always the same, and what it is.
+func compareBounds(op token.Token, lo, hi, k int64) (known bool, result bool) {
+	switch op {
+	case token.LSS:
+		return hi < k || lo >= k, hi < k
+	case token.LEQ:
+		return hi <= k || lo > k, hi <= k
+	case token.GTR:
+		return lo > k || hi <= k, lo > k
+	case token.GEQ:
+		return lo >= k || hi < k, lo >= k
+	case token.EQL:
+		if k < lo || k > hi {
+			return true, false
+		}
+		return lo == hi, true
+	case token.NEQ:
+		known, result := compareBounds(token.EQL, lo, hi, k)
+		return known, !result
+	}
+	return false, false
+}
+
+func (c *Checker) CheckAlwaysSameComparison(j *lint.Job) {
+	flip := map[token.Token]token.Token{
+		token.LSS: token.GTR,
+		token.LEQ: token.GEQ,
+		token.GTR: token.LSS,
+		token.GEQ: token.LEQ,
+		token.EQL: token.EQL,
+		token.NEQ: token.NEQ,
+	}
+	calleeName := func(call *ast.CallExpr) string {
+		var ident *ast.Ident
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			ident = fun
+		case *ast.SelectorExpr:
+			ident = fun.Sel
+		default:
+			return ""
+		}
+		switch obj := j.Program.Info.ObjectOf(ident).(type) {
+		case *types.Builtin:
+			return obj.Name()
+		case *types.Func:
+			return obj.FullName()
+		}
+		return ""
+	}
+	fn := func(node ast.Node) bool {
+		expr, ok := node.(*ast.BinaryExpr)
+		if !ok {
+			return true
+		}
+		op, ok := flip[expr.Op]
+		if !ok {
+			return true
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		k := j.Program.Info.Types[expr.Y].Value
+		if !ok || k == nil {
+			call, ok = expr.Y.(*ast.CallExpr)
+			k = j.Program.Info.Types[expr.X].Value
+			if !ok || k == nil {
+				return true
+			}
+		} else {
+			op = expr.Op
+		}
+		name := calleeName(call)
+
+		var known, result bool
+		switch k.Kind() {
+		case constant.Int:
+			bounds, ok := resultBounds[name]
+			if !ok {
+				return true
+			}
+			v, exact := constant.Int64Val(k)
+			if !exact {
+				return true
+			}
+			known, result = compareBounds(op, bounds[0], bounds[1], v)
+		case constant.String:
+			canon, ok := resultCanonicalizers[name]
+			if !ok || (op != token.EQL && op != token.NEQ) {
+				return true
+			}
+			v := constant.StringVal(k)
+			if canon(v) == v {
+				return true
+			}
+			known, result = true, op == token.NEQ
+		}
+		if known {
+			j.Errorf(expr, "the result of %s can never be such that this comparison is %t; it is always %t", j.Render(call), !result, result)
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckMapBytesKey(j *lint.Job) {
+	for _, fn := range j.Program.InitialFunctions {
+		for _, b := range fn.Blocks {
+		insLoop:
+			for _, ins := range b.Instrs {
+				// find []byte -> string conversions
+				conv, ok := ins.(*ssa.Convert)
+				if !ok || conv.Type() != types.Universe.Lookup("string").Type() {
+					continue
+				}
+				if s, ok := conv.X.Type().(*types.Slice); !ok || s.Elem() != types.Universe.Lookup("byte").Type() {
+					continue
+				}
+				refs := conv.Referrers()
+				// need at least two (DebugRef) references: the
+				// conversion and the *ast.Ident
+				if refs == nil || len(*refs) < 2 {
+					continue
+				}
+				ident := false
+				// skip first reference, that's the conversion itself
+				for _, ref := range (*refs)[1:] {
+					switch ref := ref.(type) {
+					case *ssa.DebugRef:
+						if _, ok := ref.Expr.(*ast.Ident); !ok {
+							// the string seems to be used somewhere
+							// unexpected; the default branch should
+							// catch this already, but be safe
+							continue insLoop
+						} else {
+							ident = true
+						}
+					case *ssa.Lookup:
+					default:
+						// the string is used somewhere else than a
+						// map lookup
+						continue insLoop
+					}
+				}
+
+				// the result of the conversion wasn't assigned to an
+				// identifier
+				if !ident {
+					continue
+				}
+				j.Errorf(conv, "m[string(key)] would be more efficient than k := string(key); m[k]")
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckRangeStringRunes(j *lint.Job) {
+	sharedcheck.CheckRangeStringRunes(c.nodeFns, j)
+}
+
+func (c *Checker) CheckSelfAssignment(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok {
+			return true
+		}
+		if assign.Tok != token.ASSIGN || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, stmt := range assign.Lhs {
+			rlh := j.Render(stmt)
+			rrh := j.Render(assign.Rhs[i])
+			if rlh == rrh {
+				j.Errorf(assign, "self-assignment of %s to %s", rrh, rlh)
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckRecover(j *lint.Job) {
+	isBuiltinCall := func(node ast.Node, name string) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		ident, ok := astutil.Unparen(call.Fun).(*ast.Ident)
+		return ok && j.Program.Info.ObjectOf(ident) == types.Universe.Lookup(name)
+	}
+	funcIdent := func(expr ast.Expr) *ast.Ident {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.Ident:
+			return expr
+		case *ast.SelectorExpr:
+			return expr.Sel
+		}
+		return nil
+	}
+
+	// Find out which functions are deferred, which are only ever
+	// called directly, and which are used in other ways, such as
+	// being passed around as values.
+	deferredIdents := map[*ast.Ident]bool{}
+	calledIdents := map[*ast.Ident]bool{}
+	deferredCalls := map[*ast.CallExpr]bool{}
+	// Function literals that are called without being deferred.
+	calledLits := map[*ast.FuncLit]bool{}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.DeferStmt:
+				deferredCalls[node.Call] = true
+				if ident := funcIdent(node.Call.Fun); ident != nil {
+					deferredIdents[ident] = true
+				}
+				if isBuiltinCall(node.Call, "recover") {
+					j.Errorf(node, "defer recover() has no effect; recover has to be called by a deferred function, not be deferred itself")
+				}
+			case *ast.CallExpr:
+				if deferredCalls[node] {
+					break
+				}
+				if lit, ok := astutil.Unparen(node.Fun).(*ast.FuncLit); ok {
+					calledLits[lit] = true
+				} else if ident := funcIdent(node.Fun); ident != nil {
+					calledIdents[ident] = true
+				}
+			}
+			return true
+		})
+	}
+	deferred := map[types.Object]bool{}
+	escapes := map[types.Object]bool{}
+	used := map[types.Object]bool{}
+	for ident, obj := range j.Program.Info.Uses {
+		if _, ok := obj.(*types.Func); !ok {
+			continue
+		}
+		used[obj] = true
+		switch {
+		case deferredIdents[ident]:
+			deferred[obj] = true
+		case !calledIdents[ident]:
+			escapes[obj] = true
+		}
+	}
+
+	// neverDeferred reports whether the function declared by decl
+	// can't possibly be deferred.
+	neverDeferred := func(decl *ast.FuncDecl) bool {
+		obj := j.Program.Info.ObjectOf(decl.Name)
+		if obj == nil || decl.Recv != nil {
+			// Methods may be deferred through interfaces.
+			return false
+		}
+		if decl.Name.Name == "init" || (decl.Name.Name == "main" && obj.Pkg().Name() == "main") {
+			return true
+		}
+		// Unused functions are the domain of unused.
+		return used[obj] && !obj.Exported() && !deferred[obj] && !escapes[obj]
+	}
+
+	// checkBody looks at the calls of recover and panic in a single
+	// function body, excluding nested function literals.
+	checkBody := func(name string, body *ast.BlockStmt, direct func() bool) {
+		var recovers []*ast.CallExpr
+		var panics []*ast.CallExpr
+		// The variables the results of recover are assigned to.
+		vars := map[types.Object]bool{}
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.AssignStmt:
+				if len(node.Lhs) == 1 && len(node.Rhs) == 1 && isBuiltinCall(node.Rhs[0], "recover") {
+					if ident, ok := node.Lhs[0].(*ast.Ident); ok {
+						if obj := j.Program.Info.ObjectOf(ident); obj != nil {
+							vars[obj] = true
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if len(node.Names) == 1 && len(node.Values) == 1 && isBuiltinCall(node.Values[0], "recover") {
+					if obj := j.Program.Info.ObjectOf(node.Names[0]); obj != nil {
+						vars[obj] = true
+					}
+				}
+			case *ast.CallExpr:
+				switch {
+				case isBuiltinCall(node, "recover"):
+					recovers = append(recovers, node)
+				case isBuiltinCall(node, "panic"):
+					panics = append(panics, node)
+				}
+			}
+			return true
+		})
+		if len(recovers) == 0 {
+			return
+		}
+		if direct() {
+			for _, call := range recovers {
+				j.Errorf(call, "recover has no effect here; it has to be called directly by a deferred function, and %s is never deferred", name)
+			}
+			return
+		}
+
+		// The recovered value is lost if it is never used, other
+		// than to compare it against nil.
+		used := false
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.BinaryExpr:
+				if j.Program.Info.Types[node.X].IsNil() || j.Program.Info.Types[node.Y].IsNil() {
+					return false
+				}
+			case *ast.Ident:
+				if vars[j.Program.Info.Uses[node]] {
+					used = true
+				}
+			}
+			return true
+		})
+		if used {
+			return
+		}
+		for _, call := range panics {
+			if call.Pos() > recovers[0].Pos() {
+				j.Errorf(call, "panicking with a new value discards the recovered value and with it the cause of the original panic")
+			}
+		}
+	}
+
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				if node.Body != nil {
+					checkBody(node.Name.Name, node.Body, func() bool { return neverDeferred(node) })
+				}
+			case *ast.FuncLit:
+				checkBody("the function literal", node.Body, func() bool { return calledLits[node] })
+			}
+			return true
+		})
+	}
+}
+
+func (c *Checker) CheckChannelOps(j *lint.Job) {
+	isNil := func(v ssa.Value) bool {
+		k, ok := v.(*ssa.Const)
+		return ok && k.Value == nil
+	}
+	closeArg := func(ins ssa.Instruction) (ssa.Value, bool) {
+		call, ok := ins.(*ssa.Call)
+		if !ok || call.Call.IsInvoke() {
+			return nil, false
+		}
+		if builtin, ok := call.Call.Value.(*ssa.Builtin); !ok || builtin.Name() != "close" {
+			return nil, false
+		}
+		return call.Call.Args[0], true
+	}
+	// closedBefore reports whether ch has definitely been closed
+	// when ins executes, because a call to close in the same or a
+	// dominating block precedes it.
+	closedBefore := func(ch ssa.Value, ins ssa.Instruction, closes map[ssa.Value][]ssa.Instruction) bool {
+		for _, cl := range closes[ch] {
+			if cl == ins {
+				continue
+			}
+			if cl.Block() != ins.Block() {
+				if cl.Block().Dominates(ins.Block()) {
+					return true
+				}
+				continue
+			}
+			for _, other := range ins.Block().Instrs {
+				if other == cl {
+					return true
+				}
+				if other == ins {
+					break
+				}
+			}
+		}
+		return false
+	}
+	for _, ssafn := range j.Program.InitialFunctions {
+		closes := map[ssa.Value][]ssa.Instruction{}
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				if ch, ok := closeArg(ins); ok {
+					closes[ch] = append(closes[ch], ins)
+				}
+			}
+		}
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				switch ins := ins.(type) {
+				case *ssa.Send:
+					if isNil(ins.Chan) {
+						j.Errorf(ins, "send on nil channel blocks forever")
+					} else if closedBefore(ins.Chan, ins, closes) {
+						j.Errorf(ins, "send on closed channel panics")
+					}
+				case *ssa.UnOp:
+					if ins.Op == token.ARROW && isNil(ins.X) {
+						j.Errorf(ins, "receive from nil channel blocks forever")
+					}
+				case *ssa.Call:
+					ch, ok := closeArg(ins)
+					if !ok {
+						break
+					}
+					if isNil(ch) {
+						j.Errorf(ins, "close of nil channel panics")
+					} else if closedBefore(ch, ins, closes) {
+						j.Errorf(ins, "close of closed channel panics")
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckSliceCapacity(j *lint.Job) {
+	isBuiltinCall := func(node ast.Node, name string) (*ast.CallExpr, bool) {
+		call, ok := astutil.Unparen(node.(ast.Expr)).(*ast.CallExpr)
+		if !ok {
+			return nil, false
+		}
+		ident, ok := astutil.Unparen(call.Fun).(*ast.Ident)
+		return call, ok && j.Program.Info.ObjectOf(ident) == types.Universe.Lookup(name)
+	}
+	objectOf := func(expr ast.Expr) types.Object {
+		ident, ok := astutil.Unparen(expr).(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		return j.Program.Info.ObjectOf(ident)
+	}
+	// refersTo reports whether node refers to obj, skipping function
+	// literals if noFuncLits is set.
+	refersTo := func(node ast.Node, obj types.Object, noFuncLits bool) bool {
+		found := false
+		ast.Inspect(node, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				return !noFuncLits
+			case *ast.Ident:
+				if j.Program.Info.ObjectOf(node) == obj {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	}
+	loopBody := func(stmt ast.Stmt) *ast.BlockStmt {
+		switch stmt := stmt.(type) {
+		case *ast.ForStmt:
+			return stmt.Body
+		case *ast.RangeStmt:
+			return stmt.Body
+		}
+		return nil
+	}
+	// appendsTo reports whether body appends to obj, as in
+	// obj = append(obj, ...), and doesn't index it.
+	appendsTo := func(body *ast.BlockStmt, obj types.Object) bool {
+		appends, indexes := false, false
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				if len(node.Lhs) != 1 || len(node.Rhs) != 1 || objectOf(node.Lhs[0]) != obj {
+					break
+				}
+				if call, ok := isBuiltinCall(node.Rhs[0], "append"); ok && len(call.Args) > 0 && objectOf(call.Args[0]) == obj {
+					appends = true
+				}
+			case *ast.IndexExpr:
+				if objectOf(node.X) == obj {
+					indexes = true
+				}
+			case *ast.SliceExpr:
+				if objectOf(node.X) == obj {
+					indexes = true
+				}
+			}
+			return true
+		})
+		return appends && !indexes
+	}
+
+	// checkMakeAppend flags make([]T, n) followed by a loop that
+	// appends to the slice.
+	checkMakeAppend := func(block *ast.BlockStmt) {
+		for i, stmt := range block.List {
+			if i == len(block.List)-1 {
+				break
+			}
+			assign, ok := stmt.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				continue
+			}
+			call, ok := isBuiltinCall(assign.Rhs[0], "make")
+			if !ok || len(call.Args) != 2 {
+				continue
+			}
+			if _, ok := j.Program.Info.TypeOf(call.Args[0]).Underlying().(*types.Slice); !ok {
+				continue
+			}
+			if n, ok := j.ExprToInt(call.Args[1]); ok && n == 0 {
+				continue
+			}
+			obj := objectOf(assign.Lhs[0])
+			body := loopBody(block.List[i+1])
+			if obj == nil || body == nil || !appendsTo(body, obj) {
+				continue
+			}
+			j.Errorf(call, "%s creates a slice of length %s, and the loop appends after those elements; did you mean make(%s, 0, %s)?",
+				j.Render(call), j.Render(call.Args[1]), j.Render(call.Args[0]), j.Render(call.Args[1]))
+		}
+	}
+
+	// checkReuse flags slices that are truncated with s = s[:0] to
+	// be reused by each iteration of a loop, but also handed to
+	// goroutines or sent on channels, which may still use them when
+	// the next iteration overwrites their contents. Statements in
+	// nested loops are seen by all enclosing loops, but only
+	// reported once.
+	reported := map[ast.Node]bool{}
+	checkReuse := func(body *ast.BlockStmt) {
+		reused := map[types.Object]bool{}
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.AssignStmt:
+				if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+					break
+				}
+				slice, ok := astutil.Unparen(node.Rhs[0]).(*ast.SliceExpr)
+				if !ok || slice.Low != nil || slice.High == nil || slice.Slice3 {
+					break
+				}
+				obj := objectOf(node.Lhs[0])
+				if n, ok := j.ExprToInt(slice.High); ok && n == 0 && obj != nil && objectOf(slice.X) == obj {
+					reused[obj] = true
+				}
+			}
+			return true
+		})
+		if len(reused) == 0 {
+			return
+		}
+		ast.Inspect(body, func(node ast.Node) bool {
+			var exprs []ast.Expr
+			switch node := node.(type) {
+			case *ast.GoStmt:
+				exprs = append(exprs, node.Call.Args...)
+				if lit, ok := node.Call.Fun.(*ast.FuncLit); ok {
+					exprs = append(exprs, lit)
+				}
+			case *ast.SendStmt:
+				exprs = append(exprs, node.Value)
+			default:
+				return true
+			}
+			if reported[node] {
+				return false
+			}
+			for obj := range reused {
+				for _, expr := range exprs {
+					if refersTo(expr, obj, false) {
+						reported[node] = true
+						j.Errorf(node, "%s is reused by the next iteration of the loop, which overwrites its contents while they may still be in use", obj.Name())
+						return false
+					}
+				}
+			}
+			return false
+		})
+	}
+
+	// Functions that return a subslice of a slice that outlives
+	// them, without limiting its capacity.
+	subslicers := map[*types.Func]bool{}
+	isLongLived := func(expr ast.Expr, sig *types.Signature) bool {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.SelectorExpr:
+			// Fields and package-level variables of other packages
+			return true
+		case *ast.Ident:
+			v, ok := j.Program.Info.ObjectOf(expr).(*types.Var)
+			if !ok {
+				return false
+			}
+			if v.Parent() == v.Pkg().Scope() {
+				return true
+			}
+			if sig.Recv() == v {
+				return true
+			}
+			for i := 0; i < sig.Params().Len(); i++ {
+				if sig.Params().At(i) == v {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, f := range j.Program.Files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			obj, ok := j.Program.Info.ObjectOf(fn.Name).(*types.Func)
+			if !ok {
+				continue
+			}
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.ReturnStmt:
+					for _, res := range node.Results {
+						slice, ok := astutil.Unparen(res).(*ast.SliceExpr)
+						if !ok || slice.Slice3 || slice.High == nil {
+							continue
+						}
+						if _, ok := j.Program.Info.TypeOf(slice.X).Underlying().(*types.Slice); ok && isLongLived(slice.X, obj.Type().(*types.Signature)) {
+							subslicers[obj] = true
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	// subslicerCall returns the function if expr is a call to one of
+	// the subslicers.
+	subslicerCall := func(expr ast.Expr) (*types.Func, bool) {
+		call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+		if !ok {
+			return nil, false
+		}
+		var ident *ast.Ident
+		switch fun := astutil.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			ident = fun
+		case *ast.SelectorExpr:
+			ident = fun.Sel
+		default:
+			return nil, false
+		}
+		fn, ok := j.Program.Info.ObjectOf(ident).(*types.Func)
+		return fn, ok && subslicers[fn]
+	}
+	// checkAppendToSubslice flags appends to the results of
+	// subslicers, which may overwrite the elements of the original
+	// slice that follow the subslice.
+	checkAppendToSubslice := func(body *ast.BlockStmt) {
+		results := map[types.Object]*types.Func{}
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+					break
+				}
+				if fn, ok := subslicerCall(node.Rhs[0]); ok {
+					if obj := objectOf(node.Lhs[0]); obj != nil {
+						results[obj] = fn
+					}
+				}
+			case *ast.CallExpr:
+				if _, ok := isBuiltinCall(node, "append"); !ok || len(node.Args) == 0 {
+					break
+				}
+				fn, ok := subslicerCall(node.Args[0])
+				if !ok {
+					fn, ok = results[objectOf(node.Args[0])]
+				}
+				if ok {
+					j.Errorf(node, "%s returns a subslice of a longer slice; appending to it overwrites the elements that follow it, unless %s limits the capacity with a full slice expression like s[i:j:j]", fn.Name(), fn.Name())
+				}
+			}
+			return true
+		})
+	}
+
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			checkMakeAppend(node)
+		case *ast.ForStmt:
+			checkReuse(node.Body)
+		case *ast.RangeStmt:
+			checkReuse(node.Body)
+		case *ast.FuncDecl:
+			if node.Body != nil {
+				checkAppendToSubslice(node.Body)
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckNilGuards(j *lint.Job) {
+	// selectorChain returns the names of an identifier or a chain of
+	// field selections like a.b.c.
+	var selectorChain func(expr ast.Expr) ([]string, bool)
+	selectorChain = func(expr ast.Expr) ([]string, bool) {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.Ident:
+			return []string{expr.Name}, true
+		case *ast.SelectorExpr:
+			names, ok := selectorChain(expr.X)
+			return append(names, expr.Sel.Name), ok
+		}
+		return nil, false
+	}
+	isMapOrSlice := func(expr ast.Expr) bool {
+		switch j.Program.Info.TypeOf(expr).Underlying().(type) {
+		case *types.Map, *types.Slice:
+			return true
+		}
+		return false
+	}
+	// nilCheck returns the operand of cond if cond is of the form
+	// "x op nil", where x is a map or slice.
+	nilCheck := func(cond ast.Expr, op token.Token) (ast.Expr, bool) {
+		binop, ok := astutil.Unparen(cond).(*ast.BinaryExpr)
+		if !ok || binop.Op != op || !j.IsNil(binop.Y) {
+			return nil, false
+		}
+		if _, ok := selectorChain(binop.X); !ok || !isMapOrSlice(binop.X) {
+			return nil, false
+		}
+		return binop.X, true
+	}
+	// modifies reports whether node assigns to x or takes its
+	// address.
+	modifies := func(node ast.Node, x string) bool {
+		found := false
+		ast.Inspect(node, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range node.Lhs {
+					if j.Render(lhs) == x {
+						found = true
+					}
+				}
+			case *ast.UnaryExpr:
+				if node.Op == token.AND && j.Render(node.X) == x {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	}
+
+	// checkNil flags the body of "if x == nil" writing to x, if x is
+	// a map, or indexing it, if x is a slice. Both panic.
+	checkNil := func(x ast.Expr, body *ast.BlockStmt) {
+		name := j.Render(x)
+		_, isMap := j.Program.Info.TypeOf(x).Underlying().(*types.Map)
+		for _, stmt := range body.List {
+			if modifies(stmt, name) {
+				return
+			}
+			ast.Inspect(stmt, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.AssignStmt:
+					if !isMap {
+						break
+					}
+					for _, lhs := range node.Lhs {
+						if index, ok := astutil.Unparen(lhs).(*ast.IndexExpr); ok && j.Render(index.X) == name {
+							j.Errorf(index, "assignment to entry in nil map %s panics", name)
+						}
+					}
+				case *ast.IncDecStmt:
+					if index, ok := astutil.Unparen(node.X).(*ast.IndexExpr); ok && isMap && j.Render(index.X) == name {
+						j.Errorf(index, "assignment to entry in nil map %s panics", name)
+					}
+				case *ast.IndexExpr:
+					if !isMap && j.Render(node.X) == name {
+						j.Errorf(node, "indexing nil slice %s panics", name)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	// checkNotNil flags the body of "if x != nil" indexing a value of
+	// the same type whose name differs from x in a single component,
+	// such as a.y or b.x instead of a.x, if x itself isn't used in
+	// the body. This usually means that one of the two was copied
+	// and not updated. Bare identifiers always differ in their only
+	// component, so x has to be a selector expression.
+	checkNotNil := func(x ast.Expr, body *ast.BlockStmt) {
+		name := j.Render(x)
+		names, _ := selectorChain(x)
+		if len(names) < 2 {
+			return
+		}
+		T := j.Program.Info.TypeOf(x)
+		used := false
+		var candidates []*ast.IndexExpr
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.Ident, *ast.SelectorExpr:
+				if j.Render(node.(ast.Expr)) == name {
+					used = true
+				}
+			case *ast.IndexExpr:
+				other, ok := selectorChain(node.X)
+				if !ok || len(other) != len(names) || !types.Identical(j.Program.Info.TypeOf(node.X), T) {
+					break
+				}
+				diff := 0
+				for i := range names {
+					if names[i] != other[i] {
+						diff++
+					}
+				}
+				if diff == 1 {
+					candidates = append(candidates, node)
+				}
+			}
+			return !used
+		})
+		if used || len(candidates) == 0 {
+			return
+		}
+		index := candidates[0]
+		j.Errorf(index, "%s is indexed after checking that %s is not nil; did you mean to check %s?",
+			j.Render(index.X), name, j.Render(index.X))
+	}
+
+	fn := func(node ast.Node) bool {
+		ifstmt, ok := node.(*ast.IfStmt)
+		if !ok {
+			return true
+		}
+		if x, ok := nilCheck(ifstmt.Cond, token.EQL); ok {
+			checkNil(x, ifstmt.Body)
+		}
+		if x, ok := nilCheck(ifstmt.Cond, token.NEQ); ok {
+			checkNotNil(x, ifstmt.Body)
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// archs32 are the 32-bit architectures, on which 64-bit atomic
+// operations require manual alignment.
+var archs32 = []string{"386", "arm", "mips", "mipsle"}
+
+// atomicCall returns the address operand of a call to one of the
+// functions in sync/atomic that operate on integers and pointers.
+func atomicCall(j *lint.Job, call *ast.CallExpr) (fn *types.Func, addr ast.Expr, ok bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil, nil, false
+	}
+	fn, ok = j.Program.Info.ObjectOf(sel.Sel).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "sync/atomic" {
+		return nil, nil, false
+	}
+	if fn.Type().(*types.Signature).Recv() != nil {
+		return nil, nil, false
+	}
+	unary, ok := astutil.Unparen(call.Args[0]).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return nil, nil, false
+	}
+	return fn, astutil.Unparen(unary.X), true
+}
+
+func (c *Checker) CheckAtomics(j *lint.Job) {
+	archs := c.Archs
+	if archs == nil {
+		archs = archs32
+	}
+
+	// Variables and fields that are accessed atomically, and the
+	// expressions doing so.
+	atomics := map[types.Object]bool{}
+	atomicExprs := map[ast.Expr]bool{}
+	// Expressions whose address is taken or that are keys in
+	// composite literals; neither reads nor writes them.
+	notAccesses := map[ast.Expr]bool{}
+	// Variables that hold copies: value receivers and parameters,
+	// and the values of range statements.
+	copies := map[types.Object]bool{}
+	recordCopies := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				obj := j.Program.Info.ObjectOf(name)
+				if obj == nil {
+					continue
+				}
+				if _, ok := obj.Type().Underlying().(*types.Pointer); !ok {
+					copies[obj] = true
+				}
+			}
+		}
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				recordCopies(node.Recv)
+				recordCopies(node.Type.Params)
+			case *ast.FuncLit:
+				recordCopies(node.Type.Params)
+			case *ast.RangeStmt:
+				if ident, ok := node.Value.(*ast.Ident); ok {
+					if obj := j.Program.Info.ObjectOf(ident); obj != nil {
+						copies[obj] = true
+					}
+				}
+			case *ast.UnaryExpr:
+				if node.Op == token.AND {
+					notAccesses[astutil.Unparen(node.X)] = true
+				}
+			case *ast.KeyValueExpr:
+				notAccesses[node.Key] = true
+			case *ast.CallExpr:
+				_, addr, ok := atomicCall(j, node)
+				if !ok {
+					break
+				}
+				atomicExprs[addr] = true
+				switch addr := addr.(type) {
+				case *ast.Ident:
+					if v, ok := j.Program.Info.ObjectOf(addr).(*types.Var); ok && v.Parent() == v.Pkg().Scope() {
+						atomics[v] = true
+					}
+				case *ast.SelectorExpr:
+					if sel := j.Program.Info.Selections[addr]; sel != nil && sel.Kind() == types.FieldVal {
+						atomics[sel.Obj()] = true
+					} else if v, ok := j.Program.Info.ObjectOf(addr.Sel).(*types.Var); ok {
+						// A qualified identifier
+						atomics[v] = true
+						atomicExprs[addr.Sel] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	// offset returns the offset of expr relative to the start of
+	// its allocation, if it can be determined.
+	var offset func(sizes *gcsizes.Sizes, expr ast.Expr) (int64, bool)
+	offset = func(sizes *gcsizes.Sizes, expr ast.Expr) (int64, bool) {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.Ident:
+			_, ok := j.Program.Info.ObjectOf(expr).(*types.Var)
+			return 0, ok
+		case *ast.SelectorExpr:
+			sel := j.Program.Info.Selections[expr]
+			if sel == nil {
+				// A qualified identifier
+				_, ok := j.Program.Info.ObjectOf(expr.Sel).(*types.Var)
+				return 0, ok
+			}
+			if sel.Kind() != types.FieldVal {
+				return 0, false
+			}
+			T := sel.Recv()
+			var off int64
+			if _, ok := T.Underlying().(*types.Pointer); !ok {
+				var ok bool
+				off, ok = offset(sizes, expr.X)
+				if !ok {
+					return 0, false
+				}
+			}
+			for _, idx := range sel.Index() {
+				if ptr, ok := T.Underlying().(*types.Pointer); ok {
+					// We can't know where pointers point to; assume
+					// the start of an allocation.
+					T = ptr.Elem()
+					off = 0
+				}
+				st, ok := T.Underlying().(*types.Struct)
+				if !ok {
+					return 0, false
+				}
+				var fields []*types.Var
+				for i := 0; i < st.NumFields(); i++ {
+					fields = append(fields, st.Field(i))
+				}
+				off += sizes.Offsetsof(fields)[idx]
+				T = st.Field(idx).Type()
+			}
+			return off, true
+		}
+		return 0, false
+	}
+	// root returns the variable at the root of a chain of field
+	// selections, unless the chain dereferences a pointer.
+	var root func(expr ast.Expr) types.Object
+	root = func(expr ast.Expr) types.Object {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.Ident:
+			return j.Program.Info.ObjectOf(expr)
+		case *ast.SelectorExpr:
+			sel := j.Program.Info.Selections[expr]
+			if sel == nil || sel.Kind() != types.FieldVal || sel.Indirect() {
+				return nil
+			}
+			if _, ok := sel.Recv().Underlying().(*types.Pointer); ok {
+				return nil
+			}
+			return root(expr.X)
+		case *ast.IndexExpr:
+			if _, ok := j.Program.Info.TypeOf(expr.X).Underlying().(*types.Array); ok {
+				return root(expr.X)
+			}
+		}
+		return nil
+	}
+
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			fn, addr, ok := atomicCall(j, node)
+			if !ok {
+				return true
+			}
+			if obj := root(addr); obj != nil && copies[obj] {
+				j.Errorf(node, "atomic operation on %s, which is part of a copy; the operation isn't visible to anyone else", j.Render(addr))
+			}
+			if !strings.HasSuffix(fn.Name(), "64") {
+				return true
+			}
+			for _, arch := range archs {
+				sizes := gcsizes.ForArch(arch)
+				if sizes.WordSize == 8 {
+					continue
+				}
+				if off, ok := offset(sizes, addr); ok && off%8 != 0 {
+					j.Errorf(node, "64-bit atomic operation on %s, which is not 64-bit aligned on %s; move it to the start of the struct", j.Render(addr), arch)
+					break
+				}
+			}
+		case *ast.SelectorExpr:
+			if atomicExprs[node] || notAccesses[node] {
+				return true
+			}
+			if sel := j.Program.Info.Selections[node]; sel != nil && sel.Kind() == types.FieldVal && atomics[sel.Obj()] {
+				j.Errorf(node, "non-atomic access to %s, which is also accessed atomically", j.Render(node))
+			}
+		case *ast.Ident:
+			if atomicExprs[node] || notAccesses[node] {
+				return true
+			}
+			if v, ok := j.Program.Info.Uses[node].(*types.Var); ok && !v.IsField() && atomics[v] {
+				j.Errorf(node, "non-atomic access to %s, which is also accessed atomically", node.Name)
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func isUnsafePointer(T types.Type) bool {
+	basic, ok := T.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.UnsafePointer
+}
+
+func isUintptr(T types.Type) bool {
+	basic, ok := T.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uintptr
+}
+
+// conversionArg returns the argument of expr if expr is a conversion
+// to a type for which fn returns true.
+func conversionArg(j *lint.Job, expr ast.Expr, fn func(types.Type) bool) (ast.Expr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, false
+	}
+	tv := j.Program.Info.Types[call.Fun]
+	if !tv.IsType() || !fn(tv.Type) {
+		return nil, false
+	}
+	return call.Args[0], true
+}
+
+func (c *Checker) CheckUnsafePointer(j *lint.Job) {
+	// safe reports whether expr is a uintptr that may be converted
+	// to unsafe.Pointer, following the patterns documented in the
+	// unsafe package.
+	var safe func(expr ast.Expr) bool
+	safe = func(expr ast.Expr) bool {
+		switch expr := expr.(type) {
+		case *ast.ParenExpr:
+			return safe(expr.X)
+		case *ast.SelectorExpr:
+			// The Data field of reflect.SliceHeader and
+			// reflect.StringHeader.
+			sel := j.Program.Info.Selections[expr]
+			if sel == nil || sel.Kind() != types.FieldVal || expr.Sel.Name != "Data" {
+				return false
+			}
+			T := sel.Recv()
+			if ptr, ok := T.(*types.Pointer); ok {
+				T = ptr.Elem()
+			}
+			switch types.TypeString(T, nil) {
+			case "reflect.SliceHeader", "reflect.StringHeader":
+				return true
+			}
+		case *ast.CallExpr:
+			if arg, ok := conversionArg(j, expr, isUintptr); ok {
+				return isUnsafePointer(j.Program.Info.TypeOf(arg))
+			}
+			return j.IsCallToAnyAST(expr, "(reflect.Value).Pointer", "(reflect.Value).UnsafeAddr")
+		case *ast.BinaryExpr:
+			switch expr.Op {
+			case token.ADD, token.SUB, token.AND_NOT:
+				return safe(expr.X) && !safe(expr.Y)
+			}
+		}
+		return false
+	}
+
+	sizes := j.Program.Sizes
+	// checkBounds flags arithmetic of the form
+	// uintptr(unsafe.Pointer(&v)) + off that leaves the variable v.
+	checkBounds := func(expr ast.Expr) {
+		bin, ok := astutil.Unparen(expr).(*ast.BinaryExpr)
+		if !ok || (bin.Op != token.ADD && bin.Op != token.SUB) {
+			return
+		}
+		k := j.Program.Info.Types[bin.Y].Value
+		if k == nil {
+			return
+		}
+		off, exact := constant.Int64Val(constant.ToInt(k))
+		if !exact {
+			return
+		}
+		if bin.Op == token.SUB {
+			off = -off
+		}
+		arg, ok := conversionArg(j, astutil.Unparen(bin.X), isUintptr)
+		if !ok {
+			return
+		}
+		arg, ok = conversionArg(j, astutil.Unparen(arg), isUnsafePointer)
+		if !ok {
+			return
+		}
+		addr, ok := astutil.Unparen(arg).(*ast.UnaryExpr)
+		if !ok || addr.Op != token.AND {
+			return
+		}
+		ident, ok := astutil.Unparen(addr.X).(*ast.Ident)
+		if !ok {
+			return
+		}
+		v, ok := j.Program.Info.ObjectOf(ident).(*types.Var)
+		if !ok || v.IsField() {
+			return
+		}
+		if size := sizes.Sizeof(v.Type()); off < 0 || off >= size {
+			j.Errorf(bin, "pointer arithmetic leaves the bounds of %s, which is %d bytes large", ident.Name, size)
+		}
+	}
+
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		arg, ok := conversionArg(j, call, isUnsafePointer)
+		if !ok || !isUintptr(j.Program.Info.TypeOf(arg)) {
+			return true
+		}
+		if !safe(arg) {
+			j.Errorf(call, "converting %s to unsafe.Pointer is invalid; the uintptr has to be derived from a pointer in the same expression", j.Render(arg))
+			return true
+		}
+		checkBounds(arg)
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// errorString returns the constant message of a call to errors.New or
+// fmt.Errorf.
+func errorString(j *lint.Job, call *ast.CallExpr) (string, bool) {
+	if !j.IsCallToAST(call, "errors.New") && !j.IsCallToAST(call, "fmt.Errorf") {
+		return "", false
+	}
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	tv := j.Program.Info.Types[call.Args[0]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func (c *Checker) CheckErrorStrings(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		s, ok := errorString(j, call)
+		if !ok || s == "" {
+			return true
+		}
+		first, n := utf8.DecodeRuneInString(s)
+		next, _ := utf8.DecodeRuneInString(s[n:])
+		// Words like "URL" or "ID" are allowed to start an error
+		// string.
+		if unicode.IsUpper(first) && !unicode.IsUpper(next) {
+			j.Errorf(call.Args[0], "error strings should not be capitalized")
+		}
+		switch s[len(s)-1] {
+		case '.', ':', '!', '\n':
+			j.Errorf(call.Args[0], "error strings should not end with punctuation or newlines")
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckErrorfRepeatedText(j *lint.Job) {
+	// Messages of errors stored in variables, such as sentinel
+	// errors.
+	messages := map[types.Object]string{}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			spec, ok := node.(*ast.ValueSpec)
+			if !ok || len(spec.Names) != len(spec.Values) {
+				return true
+			}
+			for i, name := range spec.Names {
+				call, ok := spec.Values[i].(*ast.CallExpr)
+				if !ok || !j.IsCallToAST(call, "errors.New") {
+					continue
+				}
+				if s, ok := errorString(j, call); ok && s != "" {
+					messages[j.Program.Info.ObjectOf(name)] = s
+				}
+			}
+			return true
+		})
+	}
+
+	objectOf := func(expr ast.Expr) types.Object {
+		switch expr := expr.(type) {
+		case *ast.Ident:
+			return j.Program.Info.ObjectOf(expr)
+		case *ast.SelectorExpr:
+			return j.Program.Info.ObjectOf(expr.Sel)
+		}
+		return nil
+	}
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn, verbs, args, ok := printfCall(j.Program.Info, call)
+		if !ok || fn.FullName() != "fmt.Errorf" {
+			return true
+		}
+		format := constant.StringVal(j.Program.Info.Types[call.Args[0]].Value)
+		seen := map[types.Object]bool{}
+		for _, verb := range verbs {
+			if verb.arg >= len(args) {
+				continue
+			}
+			arg := args[verb.arg]
+			obj, ok := objectOf(arg).(*types.Var)
+			if !ok || !isError(obj.Type()) {
+				continue
+			}
+			if seen[obj] {
+				j.Errorf(arg, "%s is formatted more than once, repeating its message", j.Render(arg))
+				continue
+			}
+			seen[obj] = true
+			if msg, ok := messages[obj]; ok && strings.Contains(format, msg) {
+				j.Errorf(call.Args[0], "error message repeats the message of %s, %q", j.Render(arg), msg)
 			}
 		}
 		return true
@@ -0,0 +1,115 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+	"unicode/utf8"
+)
+
+// printfFuncs maps Printf-style functions to the index of their
+// format argument.
+var printfFuncs = map[string]int{
+	"fmt.Errorf":               0,
+	"fmt.Fprintf":              1,
+	"fmt.Printf":               0,
+	"fmt.Sprintf":              0,
+	"log.Fatalf":               0,
+	"log.Panicf":               0,
+	"log.Printf":               0,
+	"(*log.Logger).Fatalf":     0,
+	"(*log.Logger).Panicf":     0,
+	"(*log.Logger).Printf":     0,
+	"(*testing.common).Errorf": 0,
+	"(*testing.common).Fatalf": 0,
+	"(*testing.common).Logf":   0,
+	"(*testing.common).Skipf":  0,
+}
+
+type printfVerb struct {
+	verb rune
+	// arg is the index of the argument the verb consumes, relative
+	// to the first argument after the format string.
+	arg int
+}
+
+// parsePrintfVerbs returns the verbs in a Printf-style format
+// string. It returns false for formats that use explicit argument
+// indices, which aren't supported.
+func parsePrintfVerbs(format string) ([]printfVerb, bool) {
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	var verbs []printfVerb
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) != -1 {
+			i++
+		}
+		if i < len(format) && format[i] == '*' {
+			arg++
+			i++
+		} else {
+			for i < len(format) && isDigit(format[i]) {
+				i++
+			}
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			if i < len(format) && format[i] == '*' {
+				arg++
+				i++
+			} else {
+				for i < len(format) && isDigit(format[i]) {
+					i++
+				}
+			}
+		}
+		if i >= len(format) {
+			break
+		}
+		switch format[i] {
+		case '[':
+			return nil, false
+		case '%':
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(format[i:])
+		verbs = append(verbs, printfVerb{verb: r, arg: arg})
+		arg++
+		i += size - 1
+	}
+	return verbs, true
+}
+
+// printfCall returns the called function, the verbs and the operands
+// of a call to a Printf-style function with a constant format string.
+func printfCall(info *types.Info, call *ast.CallExpr) (fn *types.Func, verbs []printfVerb, args []ast.Expr, ok bool) {
+	if call.Ellipsis.IsValid() {
+		return nil, nil, nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	fn, ok = info.ObjectOf(sel.Sel).(*types.Func)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	idx, ok := printfFuncs[fn.FullName()]
+	if !ok || len(call.Args) <= idx {
+		return nil, nil, nil, false
+	}
+	tv, ok := info.Types[call.Args[idx]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return nil, nil, nil, false
+	}
+	verbs, ok = parsePrintfVerbs(constant.StringVal(tv.Value))
+	if !ok {
+		return nil, nil, nil, false
+	}
+	return fn, verbs, call.Args[idx+1:], true
+}
@@ -0,0 +1,27 @@
+package pkg
+
+import "strings"
+
+func fn(s string, b []byte) {
+	_ = len(s) < 0                      // MATCH /this comparison is true; it is always false/
+	_ = len(b) >= 0                     // MATCH /always true/
+	_ = 0 > cap(b)                      // MATCH /always false/
+	_ = len(s) == -1                    // MATCH /always false/
+	_ = len(s) != -1                    // MATCH /always true/
+	_ = strings.Index(s, "a") < -1      // MATCH /always false/
+	_ = strings.IndexByte(s, 'a') >= -1 // MATCH /always true/
+	_ = strings.Compare(s, "a") == 2    // MATCH /always false/
+	_ = strings.Count(s, "a") < 0       // MATCH /always false/
+	_ = strings.ToLower(s) == "Foo"     // MATCH /strings.ToLower\(s\) can never be such that this comparison is true; it is always false/
+	_ = strings.ToUpper(s) != "foo"     // MATCH /always true/
+	_ = "foo " == strings.TrimSpace(s)  // MATCH /always false/
+
+	_ = len(s) == 0
+	_ = len(s) > 0
+	_ = strings.Index(s, "a") == -1
+	_ = strings.Compare(s, "a") < 0
+	_ = strings.ToLower(s) == "foo"
+	_ = strings.ToUpper(s) == "FOO"
+	_ = strings.TrimSpace(s) == "foo bar"
+	_ = strings.ToLower(s) < "Foo"
+}
@@ -0,0 +1,63 @@
+package pkg
+
+import "sync/atomic"
+
+var counter int64
+var plain int64
+
+type T1 struct {
+	n     int64
+	flag  int32
+	count int64
+	other int64
+}
+
+type T2 struct {
+	b  bool
+	t1 T1
+}
+
+type T3 struct {
+	b bool
+	p *T1
+}
+
+func fn1(t *T1, t2 *T2, t3 *T3) {
+	atomic.AddInt64(&counter, 1)
+	_ = counter // MATCH /non-atomic access to counter/
+	counter = 0 // MATCH /non-atomic access to counter/
+	_ = plain
+	plain = 1
+
+	atomic.AddInt64(&t.n, 1)
+	_ = t.n // MATCH /non-atomic access to t.n/
+	_ = t.other
+	_ = &t.n
+
+	atomic.StoreInt32(&t.flag, 1)
+	atomic.AddInt64(&t.count, 1) // MATCH /not 64-bit aligned on 386/
+	atomic.AddInt64(&t2.t1.n, 1) // MATCH /not 64-bit aligned on 386/
+	atomic.AddInt64(&t3.p.n, 1)
+	_ = T1{n: 1}
+}
+
+func (t T1) fn2() {
+	atomic.StoreInt32(&t.flag, 1) // MATCH /part of a copy/
+}
+
+func (t *T1) fn3() {
+	atomic.StoreInt32(&t.flag, 1)
+}
+
+func fn4(t T1, ts []T1) {
+	atomic.StoreInt32(&t.flag, 1) // MATCH /part of a copy/
+	for _, t := range ts {
+		atomic.StoreInt32(&t.flag, 1) // MATCH /part of a copy/
+	}
+	for i := range ts {
+		atomic.StoreInt32(&ts[i].flag, 1)
+	}
+	var local int32
+	atomic.AddInt32(&local, 1)
+	_ = local
+}
@@ -0,0 +1,10 @@
+// +build linux darwin
+// +build !windows
+
+//go:build (linux || darwin) && !windows
+
+package pkg
+
+// +build linux // MATCH /after the package clause will be ignored/
+
+func fn() {}
@@ -0,0 +1,6 @@
+//go:build linux || windows
+// +build linux
+
+package pkg
+
+// MATCH:1 /doesn't match \+build lines/
@@ -0,0 +1,5 @@
+// +build linux
+// Package pkg does things.
+package pkg
+
+// MATCH:1 /must be followed by a blank line/
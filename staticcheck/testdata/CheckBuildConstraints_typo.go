@@ -0,0 +1,5 @@
+// go:build ignore
+
+package pkg
+
+// MATCH:1 /must not have a space/
@@ -0,0 +1,6 @@
+//go:build linux && windows
+// +build linux,windows
+
+package pkg
+
+// MATCH:1 /can never be satisfied/
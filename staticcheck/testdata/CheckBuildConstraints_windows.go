@@ -0,0 +1,5 @@
+// +build unix
+
+package pkg
+
+// MATCH:1 /can never be satisfied/
@@ -0,0 +1,54 @@
+package pkg
+
+func fn1() {
+	var ch chan int
+	ch <- 1   // MATCH /send on nil channel blocks forever/
+	<-ch      // MATCH /receive from nil channel blocks forever/
+	close(ch) // MATCH /close of nil channel panics/
+}
+
+func fn2(ch chan int, b bool) {
+	close(ch)
+	ch <- 1   // MATCH /send on closed channel panics/
+	close(ch) // MATCH /close of closed channel panics/
+	_ = <-ch
+}
+
+func fn3(ch chan int, b bool) {
+	if b {
+		close(ch)
+	}
+	ch <- 1
+}
+
+func fn4(ch chan int, b bool) {
+	if b {
+		ch <- 1
+		return
+	}
+	close(ch)
+}
+
+func fn5(ch chan int, b bool) {
+	close(ch)
+	if b {
+		ch <- 1 // MATCH /send on closed channel panics/
+	}
+}
+
+func fn6() {
+	var ch chan int
+	if true {
+		ch = make(chan int, 1)
+	}
+	ch <- 1
+	select {
+	case <-ch:
+	default:
+	}
+}
+
+func fn7(a, b chan int) {
+	close(a)
+	b <- 1
+}
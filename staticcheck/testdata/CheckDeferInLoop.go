@@ -0,0 +1,66 @@
+package pkg
+
+import "os"
+
+func fn1(names []string, ch chan int) {
+	for _, name := range names {
+		f, _ := os.Open(name)
+		defer f.Close() // MATCH /defers in this loop only run when the surrounding function returns/
+	}
+
+	for i := 0; i < len(names); i++ {
+		f, _ := os.Open(names[i])
+		defer f.Close() // MATCH /defers in this loop/
+	}
+
+	for _, name := range names {
+		func() {
+			f, _ := os.Open(name)
+			defer f.Close()
+		}()
+	}
+
+	for i := 0; i < 3; i++ {
+		defer println(i)
+	}
+
+	for i := 1; i <= 10; i++ {
+		defer println(i)
+	}
+
+	for i := 0; i < 100; i++ {
+		defer println(i) // MATCH /defers in this loop/
+	}
+
+	var arr [4]int
+	for i := range arr {
+		defer println(i)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		f, _ := os.Open(name)
+		defer f.Close()
+	}
+
+	for _, name := range names {
+		for i := 0; i < 2; i++ {
+			defer println(name) // MATCH /defers in this loop/
+		}
+	}
+
+	for range ch {
+		defer println() // MATCH /range loop won.t run/
+	}
+}
+
+func fn2(f *os.File) {
+	var s struct{ i int }
+	for s.i = 0; s.i < 20; s.i++ {
+		defer f.Close() // MATCH /defers in this loop/
+	}
+
+	var a [1]int
+	for a[0] = 0; a[0] < 20; a[0]++ {
+		defer f.Close() // MATCH /defers in this loop/
+	}
+}
@@ -36,3 +36,46 @@ func fn2() {
 		println()
 	}
 }
+
+type Response struct {
+	Body io.ReadCloser
+}
+
+func get() (*Response, error) {
+	return nil, nil
+}
+
+func fn4() {
+	resp, err := get()
+	println()
+	defer resp.Body.Close() // MATCH /should check the error returned by .*get before deferring a call that dereferences its result/
+	if err != nil {
+		return
+	}
+}
+
+func fn5() error {
+	resp, err := get()
+	if err != nil {
+		return err
+	}
+	println()
+	defer resp.Body.Close()
+	return nil
+}
+
+func fn6(b bool) {
+	resp, err := get()
+	if b {
+		defer resp.Body.Close() // MATCH /should check the error returned by/
+	}
+	if err != nil {
+		return
+	}
+}
+
+func fn7() {
+	resp, _ := get()
+	println()
+	defer resp.Body.Close()
+}
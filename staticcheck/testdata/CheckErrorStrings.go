@@ -0,0 +1,22 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+func fn(n int) []error {
+	return []error{
+		errors.New("Something went wrong"),  // MATCH /should not be capitalized/
+		errors.New("something went wrong."), // MATCH /should not end with punctuation/
+		fmt.Errorf("bad value %d:", n),      // MATCH /should not end with punctuation/
+		errors.New("oops!"),                 // MATCH /should not end with punctuation/
+		errors.New("oops\n"),                // MATCH /should not end with punctuation or newlines/
+
+		errors.New("something went wrong"),
+		errors.New("URL is invalid"),
+		errors.New("ID must not be empty"),
+		fmt.Errorf("bad value %d", n),
+		errors.New(""),
+	}
+}
@@ -0,0 +1,22 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("not found")
+
+var errEmpty, errLong = errors.New("empty"), errors.New("too long")
+
+func fn(err error, name string) []error {
+	return []error{
+		fmt.Errorf("%s not found: %v", name, ErrNotFound), // MATCH /repeats the message of ErrNotFound, "not found"/
+		fmt.Errorf("input empty: %v", errEmpty),           // MATCH /repeats the message of errEmpty/
+		fmt.Errorf("%v: %v", err, err),                    // MATCH /err is formatted more than once/
+
+		fmt.Errorf("looking up %s: %v", name, ErrNotFound),
+		fmt.Errorf("input: %v", errLong),
+		fmt.Errorf("%v: %s", err, name),
+	}
+}
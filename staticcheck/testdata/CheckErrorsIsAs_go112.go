@@ -0,0 +1,13 @@
+package pkg
+
+import "errors"
+
+type MyError struct{}
+
+func (*MyError) Error() string { return "" }
+
+func fn(err error) {
+	// errors.As doesn't exist before Go 1.13.
+	var myErr *MyError
+	errors.As(err, myErr)
+}
@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrSentinel = errors.New("sentinel")
+
+type MyError struct{}
+
+func (*MyError) Error() string { return "" }
+
+type ValueError struct{}
+
+func (ValueError) Error() string { return "" }
+
+func wrapV(err error) error {
+	return fmt.Errorf("failed: %v", err)
+}
+
+func wrapW(err error) error {
+	return fmt.Errorf("failed: %w", err)
+}
+
+func load() (int, error) {
+	if false {
+		return 0, fmt.Errorf("load: %s", ErrSentinel)
+	}
+	return 0, nil
+}
+
+func fn(err error, target interface{}) {
+	var myErr *MyError
+	var valErr ValueError
+	var iface interface{ Timeout() bool }
+	errors.As(err, &myErr)
+	errors.As(err, &valErr)
+	errors.As(err, &iface)
+	errors.As(err, target)
+	errors.As(err, myErr)  // MATCH /second argument to errors.As must be a non-nil pointer/
+	errors.As(err, valErr) // MATCH /second argument to errors.As must be a non-nil pointer/
+	errors.As(err, nil)    // MATCH /second argument to errors.As must be a non-nil pointer/
+	var n int
+	errors.As(err, &n) // MATCH /second argument to errors.As must be a non-nil pointer/
+
+	errors.Is(err, ErrSentinel)
+	errors.Is(err, errors.New("sentinel"))       // MATCH /compares against a newly constructed error/
+	errors.Is(err, fmt.Errorf("sentinel %d", 1)) // MATCH /compares against a newly constructed error/
+
+	errors.Is(wrapV(err), ErrSentinel) // MATCH "wrapV formats errors with %v instead of wrapping them with %w, so errors.Is can't find them"
+	errors.Is(wrapW(err), ErrSentinel)
+	_, err = load()
+	errors.Is(err, ErrSentinel) // MATCH /load formats errors with %s/
+	if _, err := load(); errors.Is(err, ErrSentinel) { // MATCH /load formats errors with %s/
+		println()
+	}
+}
@@ -0,0 +1,15 @@
+package pkg
+
+import "flag"
+
+var addr string
+
+func init() {
+	flag.StringVar(&addr, "addr", "", "")
+	flag.Parse() // MATCH /flag.Parse shouldn't be called during initialization/
+	_ = addr
+}
+
+func fn() string {
+	return addr
+}
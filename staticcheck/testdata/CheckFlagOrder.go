@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+var (
+	verbose = flag.Bool("v", false, "")
+	name    = flag.String("name", "", "")
+	dup     = flag.String("v", "", "") // MATCH /flag "v" has already been defined \(at .+\), defining it again panics/
+	early   = *flag.Int("early", 0, "") // MATCH /read before flag.Parse/
+	count   int
+	out     string
+)
+
+func init() {
+	flag.IntVar(&count, "n", 0, "")
+	if count > 0 { // MATCH /read before flag.Parse/
+		fmt.Println("count")
+	}
+}
+
+func setup() {
+	// Not known to always run.
+	flag.StringVar(&out, "name", "", "")
+}
+
+func main() {
+	var debug bool
+	flag.BoolVar(&debug, "debug", false, "")
+	if *verbose { // MATCH /read before flag.Parse/
+		fmt.Println("verbose")
+	}
+	flag.Parse()
+	fmt.Println(*verbose, *name, debug, count, out)
+	late := flag.Bool("late", false, "") // MATCH /defined after flag.Parse/
+	fmt.Println(*late)
+
+	fs := &flag.FlagSet{}
+	fs.String("late", "", "")
+	fs.Parse(nil)
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func fn1(m map[string]int) string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return strings.Join(keys, ",") // MATCH /keys is built by ranging over a map and its order is random; sort it before passing it to strings.Join/
+}
+
+func fn2(m map[string]int) string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func fn3(m map[string]int, want []int) bool {
+	var vals []int
+	for _, v := range m {
+		vals = append(vals, v)
+	}
+	return reflect.DeepEqual(vals, want) // MATCH /vals is built by ranging over a map/
+}
+
+func fn4(m map[string]int) []byte {
+	var vals []int
+	for _, v := range m {
+		vals = append(vals, v)
+	}
+	sort.Slice(vals, func(i, j int) bool { return vals[i] < vals[j] })
+	b, _ := json.Marshal(vals)
+	return b
+}
+
+func fn5(m map[string]int) []byte {
+	var vals []int
+	for _, v := range m {
+		vals = append(vals, v)
+	}
+	b, _ := json.Marshal(vals) // MATCH /sort it before passing it to json.Marshal/
+	fmt.Println(len(vals))
+	return b
+}
+
+func fn6(m map[string]string) []byte {
+	h := sha256.New()
+	for k, v := range m {
+		h.Write([]byte(k)) // MATCH /hashing values in map iteration order/
+		h.Write([]byte(v)) // MATCH /hashing values in map iteration order/
+	}
+	return h.Sum(nil)
+}
+
+func fn7(s []string) string {
+	var out []string
+	for _, v := range s {
+		out = append(out, v)
+	}
+	return strings.Join(out, ",")
+}
+
+func fn8(m map[string]int) int {
+	var vals []int
+	for _, v := range m {
+		vals = append(vals, v)
+	}
+	sum := 0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum
+}
@@ -0,0 +1,58 @@
+package pkg
+
+type T struct {
+	a map[string]int
+	b map[string]int
+	s []int
+}
+
+func fn1(m map[string]int, s []int, t *T) int {
+	if m == nil {
+		m["a"] = 1 // MATCH /assignment to entry in nil map m panics/
+		m["b"]++   // MATCH /assignment to entry in nil map m panics/
+	}
+	if m == nil {
+		m = map[string]int{}
+		m["a"] = 1
+	}
+	if m == nil {
+		return m["a"]
+	}
+	if t.a == nil {
+		t.a["x"] = 1 // MATCH /assignment to entry in nil map t.a panics/
+	}
+	if s == nil {
+		return s[0] // MATCH /indexing nil slice s panics/
+	}
+	if s == nil {
+		s = append(s, 1)
+		return s[0]
+	}
+	if s != nil {
+		return s[0]
+	}
+	return 0
+}
+
+func fn2(t, u *T) int {
+	if t.a != nil {
+		return t.b["x"] // MATCH /t.b is indexed after checking that t.a is not nil; did you mean to check t.b\?/
+	}
+	if t.a != nil {
+		return u.a["x"] // MATCH /u.a is indexed after checking that t.a is not nil/
+	}
+	if t.a != nil {
+		return t.a["x"] + t.b["x"]
+	}
+	if t.s != nil {
+		return t.b["x"]
+	}
+	return 0
+}
+
+func fn3(args []string, extra []string) string {
+	if extra != nil {
+		return args[0]
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type Enum int
+
+func (Enum) String() string { return "" }
+
+type Name string
+
+func fn() {
+	var i int
+	var f float64
+	var b bool
+	var s string
+	var n Name
+	var e Enum
+	var err = errors.New("")
+	var typ = reflect.TypeOf(i)
+
+	fmt.Printf("%s", i) // MATCH /%s verb used with i of type int; did you mean %d/
+	fmt.Printf("%s", f) // MATCH /did you mean %g/
+	fmt.Printf("%s", b) // MATCH /did you mean %t/
+	fmt.Printf("%s", s)
+	fmt.Printf("%s", n)
+	fmt.Printf("%s", e)
+	fmt.Printf("%d", e)
+	fmt.Printf("%d", i)
+	fmt.Printf("%d", s)   // MATCH /%d verb used with s of type string/
+	fmt.Printf("%d", err) // MATCH /which has an Error method/
+	fmt.Printf("%T", i)
+	fmt.Printf("%T", typ) // MATCH /%T verb used with a reflect.Type/
+	fmt.Printf("%v", typ)
+	fmt.Printf("%*s %s", i, s, i) // MATCH /%s verb used with i/
+	fmt.Printf("%[1]s", i)
+	fmt.Printf("%%s %s", s)
+	_ = fmt.Errorf("%v", err)
+	_ = fmt.Sprintf("%5.2s", f) // MATCH /did you mean %g/
+}
@@ -0,0 +1,14 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+func fn() {
+	var err = errors.New("")
+	_ = fmt.Errorf("foo: %v", err)
+	_ = fmt.Errorf("foo: %s", err)
+	_ = fmt.Errorf("foo: %w", err)
+	_ = fmt.Sprintf("foo: %v", err)
+}
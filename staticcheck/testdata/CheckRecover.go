@@ -0,0 +1,78 @@
+package pkg
+
+import "fmt"
+
+func init() {
+	if r := recover(); r != nil { // MATCH /recover has no effect here; it has to be called directly by a deferred function, and init is never deferred/
+		fmt.Println(r)
+	}
+}
+
+func fn1() {
+	defer recover() // MATCH /defer recover\(\) has no effect/
+}
+
+func helper() {
+	if r := recover(); r != nil { // MATCH /helper is never deferred/
+		fmt.Println(r)
+	}
+}
+
+func fn2() {
+	defer func() {
+		helper()
+	}()
+	func() {
+		recover() // MATCH /the function literal is never deferred/
+	}()
+	go func() {
+		recover() // MATCH /the function literal is never deferred/
+	}()
+}
+
+func fn3() {
+	defer func() {
+		if r := recover(); r != nil {
+			panic("something went wrong") // MATCH /discards the recovered value/
+		}
+	}()
+	defer func() {
+		if recover() != nil {
+			panic("something went wrong") // MATCH /discards the recovered value/
+		}
+	}()
+}
+
+func handler() {
+	if r := recover(); r != nil {
+		fmt.Println(r)
+	}
+}
+
+func Exported() {
+	recover()
+}
+
+func fn4() {
+	defer handler()
+	defer func() {
+		if r := recover(); r != nil {
+			panic(fmt.Sprintf("wrapped: %v", r))
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println(r)
+			panic("fatal")
+		}
+	}()
+	defer func() {
+		panic("not after a recover")
+	}()
+	h := func() {
+		recover()
+	}
+	defer h()
+	f := handler
+	_ = f
+}
@@ -0,0 +1,89 @@
+package pkg
+
+type T struct {
+	buf []byte
+}
+
+func (t *T) Bytes(n int) []byte {
+	return t.buf[:n]
+}
+
+func (t *T) Copy(n int) []byte {
+	return t.buf[:n:n]
+}
+
+func prefix(s []int, n int) []int {
+	return s[:n]
+}
+
+func fn1(xs []int) []int {
+	ys := make([]int, len(xs)) // MATCH /did you mean make\(\[\]int, 0, len\(xs\)\)/
+	for _, x := range xs {
+		ys = append(ys, x)
+	}
+
+	zs := make([]int, len(xs))
+	for i, x := range xs {
+		zs[i] = x
+	}
+
+	ws := make([]int, 0, len(xs))
+	for _, x := range xs {
+		ws = append(ws, x)
+	}
+
+	vs := make([]int, 0)
+	for _, x := range xs {
+		vs = append(vs, x)
+	}
+
+	us := make([]int, len(xs))
+	vs = append(vs, us...)
+	for _, x := range xs {
+		us = append(us, x)
+	}
+	return append(append(append(append(ys, zs...), ws...), vs...), us...)
+}
+
+func fn2(ch chan []int, process func([]int)) {
+	var buf []int
+	for i := 0; i < 10; i++ {
+		buf = buf[:0]
+		buf = append(buf, i)
+		go process(buf) // MATCH /buf is reused by the next iteration of the loop/
+		ch <- buf       // MATCH /buf is reused by the next iteration of the loop/
+		go func() {     // MATCH /buf is reused by the next iteration of the loop/
+			process(buf)
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		buf := []int{i}
+		go process(buf)
+		ch <- buf
+	}
+
+	for i := 0; i < 10; i++ {
+		buf = buf[:0]
+		buf = append(buf, i)
+		process(buf)
+	}
+
+	for i := 0; i < 10; i++ {
+		for _, x := range []int{i} {
+			for k := 0; k < x; k++ {
+				buf = buf[:0]
+				buf = append(buf, k)
+				ch <- buf // MATCH /buf is reused by the next iteration of the loop/
+			}
+		}
+	}
+}
+
+func fn3(t *T, s []int) ([]byte, []int) {
+	b := append(t.Bytes(1), 'x') // MATCH /Bytes returns a subslice of a longer slice/
+	p := prefix(s, 2)
+	p = append(p, 1) // MATCH /prefix returns a subslice of a longer slice/
+	c := append(t.Copy(1), 'x')
+	return append(b, c...), p
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type MyInt int
+
+func fn1(i int, i64 int64, m MyInt, r rune, b byte, s string) []string {
+	const k = 65
+	return []string{
+		string(i),   // MATCH /conversion from int to string yields a string of one rune/
+		string(i64), // MATCH /conversion from int64 to string/
+		string(m),   // MATCH /conversion from pkg.MyInt to string/
+		string(r),
+		string(b),
+		string(k),
+		string(rune(i)),
+	}
+}
+
+func fn2(s string) {
+	rs := []rune(s)
+	_ = rs[:len(s)]         // MATCH /len\(s\) is the length of s in bytes, not in runes; use len\(rs\) instead/
+	_ = []rune(s)[len(s)-1] // MATCH /len\(s\) is the length of s in bytes/
+	_ = rs[:len(rs)]
+	_ = s[:utf8.RuneCountInString(s)] // MATCH /utf8.RuneCountInString\(s\) is the length of s in runes/
+	_ = s[:len(s)]
+}
+
+func fn3(s string, bs []byte) []bool {
+	var out []bool
+	for i := 0; i < len(s); i++ {
+		out = append(out, unicode.IsUpper(rune(s[i]))) // MATCH /s\[i\] is a single byte of s, not a character/
+		out = append(out, unicode.IsUpper(rune(bs[i])))
+	}
+	for _, r := range s {
+		out = append(out, unicode.IsUpper(r))
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+type T struct {
+	A int
+	B []int
+}
+
+func get() *T { return &T{A: 1} }
+
+func same(t *T) *T { return t }
+
+type V struct{ n int }
+
+func (v V) Equal(o V) bool { return v.n == o.n }
+
+func TestPointers(t *testing.T) {
+	got := get()
+	want := &T{A: 1, B: []int{1}}
+	if got != want { // MATCH /this comparison is always true/
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got == new(T) { // MATCH /this comparison is always false/
+		t.Fail()
+	}
+	if got.A != want.A {
+		t.Fail()
+	}
+
+	in := &T{A: 2}
+	if same(in) != in {
+		t.Fail()
+	}
+	for i := 0; i < 2; i++ {
+		p := &T{A: i}
+		if i > 0 && p == get() { // MATCH /this comparison is always false/
+			t.Fail()
+		}
+	}
+	if got == nil {
+		t.Fail()
+	}
+}
+
+func TestDeepEqual(t *testing.T) {
+	a, b := V{1}, V{2}
+	if !reflect.DeepEqual(a, b) { // MATCH /should use a.Equal\(b\) instead of reflect.DeepEqual/
+		t.Fail()
+	}
+	if !reflect.DeepEqual(T{}, T{}) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type T struct {
+	a int32
+	b int32
+}
+
+func fn(p *T, s []byte, u uintptr) {
+	var x T
+	var arr [4]int64
+
+	addr := uintptr(unsafe.Pointer(p))
+	_ = (*int32)(unsafe.Pointer(addr))     // MATCH /converting addr to unsafe.Pointer is invalid/
+	_ = (*int32)(unsafe.Pointer(addr + 4)) // MATCH /converting addr \+ 4 to unsafe.Pointer is invalid/
+	_ = unsafe.Pointer(u)                  // MATCH /invalid/
+
+	_ = (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&x)) + 8))                    // MATCH /leaves the bounds of x, which is 8 bytes large/
+	_ = (*int64)(unsafe.Pointer(uintptr(unsafe.Pointer(&arr)) + unsafe.Sizeof(arr))) // MATCH /leaves the bounds of arr/
+	_ = (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&x)) - 4))                    // MATCH /leaves the bounds of x/
+
+	_ = (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&x)) + 4))
+	_ = (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&x)) + unsafe.Offsetof(x.b)))
+	_ = (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(p)) + 64))
+	_ = (*int32)(unsafe.Pointer((uintptr(unsafe.Pointer(p)) + 4) &^ 3))
+	_ = (*byte)(unsafe.Pointer(reflect.ValueOf(p).Pointer()))
+	_ = (*byte)(unsafe.Pointer((*reflect.SliceHeader)(unsafe.Pointer(&s)).Data))
+	_ = unsafe.Pointer(p)
+}
@@ -33,6 +33,12 @@ func (l *LintChecker) Funcs() map[string]lint.Func {
 	}
 }
 
+func (l *LintChecker) Docs() map[string]*lint.Documentation {
+	return map[string]*lint.Documentation{
+		"U1000": {Title: "Unused constant, variable, function, type or field"},
+	}
+}
+
 func typString(obj types.Object) string {
 	switch obj := obj.(type) {
 	case *types.Func: